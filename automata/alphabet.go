@@ -0,0 +1,127 @@
+package automata
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// ClassID is an id of a character equivalence class.
+type ClassID int
+
+// ClassRange is a maximal range of runes belonging to the same class.
+type ClassRange struct {
+	Interval
+	Class ClassID
+}
+
+// Alphabet partitions runes used by transitions into equivalence classes.
+// Runes in the same class behave the same in every state, so automata
+// can transit by class ids instead of intervals.
+// Runes that are not contained in any class have no transition.
+type Alphabet struct {
+	ranges  []ClassRange // sorted by L
+	classes [][]Interval // intervals of each class in ascending order
+}
+
+// newAlphabet groups atoms, which are disjoint intervals in ascending order,
+// by their transitions. Classes are numbered in the order of their smallest rune.
+func newAlphabet(atoms []Interval, trans map[StateID]map[Interval]*StateSet) *Alphabet {
+	sids := make([]StateID, 0, len(trans))
+	for sid := range trans {
+		sids = append(sids, sid)
+	}
+	sort.Slice(sids, func(i, j int) bool { return sids[i] < sids[j] })
+
+	// signature of the transitions by each atom
+	sigs := make([]map[StateID]*StateSet, len(atoms))
+	for _, sid := range sids {
+		for intv, tos := range trans[sid] {
+			i := sort.Search(len(atoms), func(i int) bool { return atoms[i].L >= intv.L })
+			for ; i < len(atoms) && atoms[i].R <= intv.R; i++ {
+				if sigs[i] == nil {
+					sigs[i] = make(map[StateID]*StateSet)
+				}
+				if ss, ok := sigs[i][sid]; ok {
+					sigs[i][sid] = ss.Union(tos)
+				} else {
+					sigs[i][sid] = tos
+				}
+			}
+		}
+	}
+
+	alpha := &Alphabet{}
+	keyToClass := make(map[string]ClassID)
+	for i, atom := range atoms {
+		key := signatureKey(sids, sigs[i])
+		cid, ok := keyToClass[key]
+		if !ok {
+			cid = ClassID(len(alpha.classes))
+			keyToClass[key] = cid
+			alpha.classes = append(alpha.classes, nil)
+		}
+		alpha.classes[cid] = appendInterval(alpha.classes[cid], atom)
+
+		n := len(alpha.ranges)
+		if n > 0 && alpha.ranges[n-1].Class == cid && alpha.ranges[n-1].R+1 == atom.L {
+			alpha.ranges[n-1].R = atom.R
+			continue
+		}
+		alpha.ranges = append(alpha.ranges, ClassRange{Interval: atom, Class: cid})
+	}
+
+	return alpha
+}
+
+func signatureKey(sids []StateID, sig map[StateID]*StateSet) string {
+	var sb strings.Builder
+	for _, sid := range sids {
+		ss, ok := sig[sid]
+		if !ok {
+			continue
+		}
+		sb.WriteString(strconv.Itoa(int(sid)))
+		sb.WriteByte(':')
+		sb.Write(ss.bs.Bytes())
+		sb.WriteByte(0)
+	}
+
+	return sb.String()
+}
+
+// appendInterval appends intv to intvs, merging it with the last one if they are adjacent.
+func appendInterval(intvs []Interval, intv Interval) []Interval {
+	n := len(intvs)
+	if n > 0 && intvs[n-1].R+1 == intv.L {
+		intvs[n-1].R = intv.R
+		return intvs
+	}
+
+	return append(intvs, intv)
+}
+
+// Size returns the number of classes.
+func (alpha *Alphabet) Size() int {
+	return len(alpha.classes)
+}
+
+// Intervals returns intervals of runes in the class c in ascending order.
+func (alpha *Alphabet) Intervals(c ClassID) []Interval {
+	return alpha.classes[c]
+}
+
+// Ranges returns maximal ranges of runes having the same class in ascending order.
+func (alpha *Alphabet) Ranges() []ClassRange {
+	return alpha.ranges
+}
+
+// ClassOf returns the class of the rune r.
+func (alpha *Alphabet) ClassOf(r int) (ClassID, bool) {
+	i := sort.Search(len(alpha.ranges), func(i int) bool { return alpha.ranges[i].R >= r })
+	if i < len(alpha.ranges) && alpha.ranges[i].L <= r {
+		return alpha.ranges[i].Class, true
+	}
+
+	return 0, false
+}
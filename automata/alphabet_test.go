@@ -0,0 +1,75 @@
+package automata_test
+
+import (
+	"testing"
+
+	"github.com/goropikari/tlex/automata"
+	"github.com/goropikari/tlex/collection"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAlphabet(t *testing.T) {
+	id0 := automata.NewStateID()
+	id1 := automata.NewStateID()
+	id2 := automata.NewStateID()
+	id3 := automata.NewStateID()
+	nfa := automata.NewNFA(
+		collection.NewSet[automata.StateID]().Insert(id0).Insert(id1).Insert(id2).Insert(id3),
+		automata.NewEpsilonTransition(),
+		automata.NewNFATransition().
+			Set(id0, automata.NewInterval('a', 'z'), id1).
+			Set(id0, automata.NewInterval('A', 'Z'), id1).
+			Set(id0, automata.NewInterval('0', '9'), id2).
+			Set(id0, automata.NewInterval('x', 'x'), id3),
+		collection.NewSet[automata.StateID]().Insert(id0),
+		collection.NewSet[automata.StateID]().Insert(id1).Insert(id2).Insert(id3),
+	)
+	nfa.SetRegexID(1)
+	dfa := nfa.ToImdNFA().ToDFA().LexerMinimize()
+	alpha := dfa.GetAlphabet()
+
+	require.Equal(t, 3, alpha.Size())
+	require.Equal(t, []automata.Interval{automata.NewInterval('0', '9')}, alpha.Intervals(0))
+	require.Equal(t, []automata.Interval{
+		automata.NewInterval('A', 'Z'),
+		automata.NewInterval('a', 'w'),
+		automata.NewInterval('y', 'z'),
+	}, alpha.Intervals(1))
+	require.Equal(t, []automata.Interval{automata.NewInterval('x', 'x')}, alpha.Intervals(2))
+	require.Equal(t, []automata.ClassRange{
+		{Interval: automata.NewInterval('0', '9'), Class: 0},
+		{Interval: automata.NewInterval('A', 'Z'), Class: 1},
+		{Interval: automata.NewInterval('a', 'w'), Class: 1},
+		{Interval: automata.NewInterval('x', 'x'), Class: 2},
+		{Interval: automata.NewInterval('y', 'z'), Class: 1},
+	}, alpha.Ranges())
+
+	tests := []struct {
+		name     string
+		r        rune
+		expected automata.ClassID
+		ok       bool
+	}{
+		{name: "digit", r: '5', expected: 0, ok: true},
+		{name: "upper", r: 'Q', expected: 1, ok: true},
+		{name: "lower", r: 'z', expected: 1, ok: true},
+		{name: "x", r: 'x', expected: 2, ok: true},
+		{name: "no class", r: '!', expected: 0, ok: false},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := alpha.ClassOf(int(tt.r))
+			require.Equal(t, tt.ok, ok)
+			require.Equal(t, tt.expected, got)
+		})
+	}
+
+	for _, s := range []string{"a", "Q", "x", "7"} {
+		_, ok := dfa.Accept(s)
+		require.True(t, ok, s)
+	}
+	_, ok := dfa.Accept("!")
+	require.False(t, ok)
+}
@@ -49,6 +49,17 @@ func (x Interval) Overlap(y Interval) bool {
 	return y.L <= x.R && x.L <= y.R
 }
 
+// Sample returns a readable rune in the interval if possible.
+func (x Interval) Sample() rune {
+	for _, r := range []rune{'a', 'A', '0', '!'} {
+		if x.L <= int(r) && int(r) <= x.R {
+			return r
+		}
+	}
+
+	return rune(x.L)
+}
+
 func (x Interval) Difference(y Interval) []Interval {
 	if !x.Overlap(y) {
 		return []Interval{x}
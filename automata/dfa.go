@@ -1,56 +1,105 @@
 package automata
 
-import "github.com/goropikari/tlex/collection"
+import (
+	"sort"
+
+	"github.com/goropikari/tlex/collection"
+	"github.com/goropikari/tlex/math"
+)
 
 type DFATransition struct {
-	delta map[StateID]map[Interval]StateID
+	alphabet *Alphabet
+	delta    map[StateID]map[ClassID]StateID
 }
 
-func NewDFATransition() *DFATransition {
+func NewDFATransition(alphabet *Alphabet) *DFATransition {
 	return &DFATransition{
-		delta: make(map[StateID]map[Interval]StateID),
+		alphabet: alphabet,
+		delta:    make(map[StateID]map[ClassID]StateID),
 	}
 }
 
+// GetMap returns transitions from sid by intervals of classes.
 func (trans *DFATransition) GetMap(sid StateID) (map[Interval]StateID, bool) {
 	mp, ok := trans.delta[sid]
-	return mp, ok
+	if !ok {
+		return nil, false
+	}
+
+	ret := make(map[Interval]StateID)
+	for cid, to := range mp {
+		for _, intv := range trans.alphabet.Intervals(cid) {
+			ret[intv] = to
+		}
+	}
+	return ret, true
+}
+
+// Get returns the destination of the transition by intv, which is a key of the map of GetMap.
+func (trans *DFATransition) Get(from StateID, intv Interval) (StateID, bool) {
+	cid, ok := trans.alphabet.ClassOf(intv.L)
+	if !ok {
+		return 0, false
+	}
+	return trans.GetClass(from, cid)
+}
+
+// Intervals returns intervals of transitions from sid in ascending order.
+func (trans *DFATransition) Intervals(sid StateID) []Interval {
+	intvs := make([]Interval, 0, len(trans.delta[sid]))
+	for cid := range trans.delta[sid] {
+		intvs = append(intvs, trans.alphabet.Intervals(cid)...)
+	}
+	sort.Slice(intvs, func(i, j int) bool { return intvs[i].L < intvs[j].L })
+
+	return intvs
 }
 
-func (trans *DFATransition) Set(from StateID, intv Interval, to StateID) {
+// GetClass returns the destination of the transition by the class cid.
+func (trans *DFATransition) GetClass(from StateID, cid ClassID) (StateID, bool) {
+	to, ok := trans.delta[from][cid]
+	return to, ok
+}
+
+// Alphabet returns the character classes which the transitions are defined over.
+func (trans *DFATransition) Alphabet() *Alphabet {
+	return trans.alphabet
+}
+
+func (trans *DFATransition) SetClass(from StateID, cid ClassID, to StateID) {
 	_, ok := trans.delta[from]
 	if !ok {
-		trans.delta[from] = map[Interval]StateID{}
+		trans.delta[from] = map[ClassID]StateID{}
 	}
 
-	trans.delta[from][intv] = to
+	trans.delta[from][cid] = to
 }
 
 func (trans *DFATransition) step(from StateID, intv Interval) (StateID, bool) {
-	if mp, ok := trans.delta[from]; ok {
-		for t, to := range mp {
-			if t.Overlap(intv) {
-				return to, true
-			}
-		}
-	}
-	return 0, false
+	return trans.Get(from, intv)
 }
 
 type DFA struct {
 	size        int
-	intvs       []Interval
+	alphabet    *Alphabet
 	states      *collection.Set[StateID]
 	trans       *DFATransition
 	initState   StateID
+	initStates  []StateID // start states. initState is the first one.
 	finStates   *collection.Set[StateID]
 	stIDToRegID StateIDToRegexID
+	accepted    map[StateID][]RegexID // all regex ids accepted at the state. nil after LexerMinimize.
 }
 
 func (dfa *DFA) GetInitState() StateID {
 	return dfa.initState
 }
 
+// GetInitStates returns start states in the order of NFA.SetStarts.
+func (dfa *DFA) GetInitStates() []StateID {
+	return dfa.initStates
+}
+
 func (dfa *DFA) GetFinStates() *collection.Set[StateID] {
 	return dfa.finStates
 }
@@ -63,6 +112,18 @@ func (dfa *DFA) GetRegexID(sid StateID) RegexID {
 	return dfa.stIDToRegID.Get(sid)
 }
 
+// GetRegexIDs returns ids of all regexs accepting the state in ascending order.
+// The first one is GetRegexID(sid). It is available only before LexerMinimize,
+// which merges states accepted by different regexs.
+func (dfa *DFA) GetRegexIDs(sid StateID) []RegexID {
+	return dfa.accepted[sid]
+}
+
+// GetAlphabet returns the character classes of the DFA.
+func (dfa *DFA) GetAlphabet() *Alphabet {
+	return dfa.alphabet
+}
+
 func (dfa *DFA) GetTransitionTable() *DFATransition {
 	return dfa.trans
 }
@@ -81,9 +142,57 @@ func (dfa *DFA) Accept(s string) (RegexID, bool) {
 	return dfa.stIDToRegID.Get(currSid), dfa.finStates.Contains(currSid)
 }
 
-// ここで入る intv は dfa.intvs に入っていることを前提としている
-func (dfa *DFA) stepIntv(sid StateID, intv Interval) (stateID StateID, nonDeadState bool) {
-	retID, ok := dfa.trans.delta[sid][intv]
+// ShortestCommonString returns one of the shortest non-empty strings accepted by both DFAs.
+// It searches the product automaton of them in breadth first order.
+func (dfa *DFA) ShortestCommonString(other *DFA) (string, bool) {
+	type pair struct {
+		x, y StateID
+	}
+	type path struct {
+		st   pair
+		text string
+	}
+
+	start := pair{dfa.initState, other.initState}
+	visited := map[pair]bool{start: true}
+	queue := []path{{st: start}}
+	for len(queue) > 0 {
+		p := queue[0]
+		queue = queue[1:]
+
+		yintvs := other.trans.Intervals(p.st.y)
+		for _, xintv := range dfa.trans.Intervals(p.st.x) {
+			for _, yintv := range yintvs {
+				if !xintv.Overlap(yintv) {
+					continue
+				}
+				intv := NewInterval(math.Max(xintv.L, yintv.L), math.Min(xintv.R, yintv.R))
+				np := path{
+					st:   pair{dfa.trans.delta[p.st.x][dfa.classOf(xintv)], other.trans.delta[p.st.y][other.classOf(yintv)]},
+					text: p.text + string(intv.Sample()),
+				}
+				if dfa.finStates.Contains(np.st.x) && other.finStates.Contains(np.st.y) {
+					return np.text, true
+				}
+				if visited[np.st] {
+					continue
+				}
+				visited[np.st] = true
+				queue = append(queue, np)
+			}
+		}
+	}
+
+	return "", false
+}
+
+func (dfa *DFA) classOf(intv Interval) ClassID {
+	cid, _ := dfa.alphabet.ClassOf(intv.L)
+	return cid
+}
+
+func (dfa *DFA) stepClass(sid StateID, cid ClassID) (stateID StateID, nonDeadState bool) {
+	retID, ok := dfa.trans.delta[sid][cid]
 	return retID, ok
 }
 
@@ -119,9 +228,9 @@ func (dfa *DFA) grouping() [][]StateID {
 			for i, s0 := range grp {
 				for _, s1 := range grp[i+1:] {
 					same := true
-					for _, intv := range dfa.intvs {
-						ns0, ok1 := dfa.stepIntv(s0, intv)
-						ns1, ok2 := dfa.stepIntv(s1, intv)
+					for cid := ClassID(0); cid < ClassID(dfa.alphabet.Size()); cid++ {
+						ns0, ok1 := dfa.stepClass(s0, cid)
+						ns1, ok2 := dfa.stepClass(s1, cid)
 
 						if ok1 != ok2 {
 							same = false
@@ -186,13 +295,17 @@ func (dfa *DFA) LexerMinimize() *DFA {
 	}
 
 	initState := uf.Find(dfa.initState)
+	initStates := make([]StateID, 0, len(dfa.initStates))
+	for _, sid := range dfa.initStates {
+		initStates = append(initStates, uf.Find(sid))
+	}
 
-	trans := NewDFATransition()
+	trans := NewDFATransition(dfa.alphabet)
 	for from, mp := range dfa.trans.delta {
-		for intv, to := range mp {
+		for cid, to := range mp {
 			from = uf.Find(from)
 			to = uf.Find(to)
-			trans.Set(from, intv, to)
+			trans.SetClass(from, cid, to)
 		}
 	}
 
@@ -205,10 +318,11 @@ func (dfa *DFA) LexerMinimize() *DFA {
 
 	return &DFA{
 		size:        states.Size(),
-		intvs:       dfa.intvs,
+		alphabet:    dfa.alphabet,
 		states:      states,
 		trans:       trans,
 		initState:   initState,
+		initStates:  initStates,
 		finStates:   finStates,
 		stIDToRegID: stIDToRegID,
 	}
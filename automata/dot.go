@@ -294,7 +294,8 @@ func (dfa DFA) ToDot() (string, error) {
 	}
 
 	edges := make(map[collection.Pair[StateID, StateID]]string)
-	for from, mp := range dfa.trans.delta {
+	for from := range dfa.trans.delta {
+		mp, _ := dfa.trans.GetMap(from)
 		for intv, to := range mp {
 			var lstr, rstr string
 			lstr = fmt.Sprintf("%v", intv.L)
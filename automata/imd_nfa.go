@@ -2,6 +2,7 @@ package automata
 
 import (
 	"errors"
+	"sort"
 
 	"github.com/goropikari/tlex/collection"
 	"github.com/goropikari/tlex/math"
@@ -33,12 +34,13 @@ func NewImdNFATransition(mp map[StateID]map[Interval]*StateSet) ImdNFATransition
 
 type ImdNFA struct {
 	size        int
-	intvs       []Interval
+	alphabet    *Alphabet
 	etrans      ImdEpsilonTransition
 	trans       ImdNFATransition
 	initStates  *StateSet
 	finStates   *StateSet
 	stIDToRegID StateIDToRegexID
+	starts      []StateID
 }
 
 func (nfa *ImdNFA) ToDFA() *DFA {
@@ -50,8 +52,17 @@ func (nfa *ImdNFA) ToDFA() *DFA {
 		panic(errors.New("cannot find initial state"))
 	}
 	states.Insert(initState)
+	initStates := make([]StateID, 0)
+	for _, ss := range nfa.startSets(nfa.eclosures()) {
+		sid, ok := stateSetDict.Get(ss)
+		if !ok {
+			panic(errors.New("cannot find initial state"))
+		}
+		states.Insert(sid)
+		initStates = append(initStates, sid)
+	}
 
-	trans := NewDFATransition()
+	trans := NewDFATransition(nfa.alphabet)
 	titer := imdTrans.iterator()
 	for titer.HasNext() {
 		fromSs, mp := titer.Next()
@@ -59,17 +70,18 @@ func (nfa *ImdNFA) ToDFA() *DFA {
 		if !ok {
 			panic(errors.New("cannot find given state"))
 		}
-		for intv, toss := range mp {
+		for cid, toss := range mp {
 			toid, ok := stateSetDict.Get(toss)
 			if !ok {
 				panic(errors.New("cannot find given state"))
 			}
-			trans.Set(fsid, intv, toid)
+			trans.SetClass(fsid, cid, toid)
 			states.Insert(toid)
 		}
 	}
 
 	stIDToRegID := NewStateIDToRegexID()
+	accepted := make(map[StateID][]RegexID)
 	finStates := collection.NewSet[StateID]()
 	fiter := imdFinStates.iterator()
 	for fiter.HasNext() {
@@ -81,48 +93,83 @@ func (nfa *ImdNFA) ToDFA() *DFA {
 		finStates.Insert(sid)
 
 		regID := nonFinStateRegexID
+		rids := collection.NewSet[RegexID]()
 		siter := ss.iterator()
 		for siter.HasNext() {
 			rid := nfa.stIDToRegID.Get(siter.Next())
 			regID = math.Min(regID, rid)
+			if rid != nonFinStateRegexID {
+				rids.Insert(rid)
+			}
 		}
 		stIDToRegID.Set(sid, regID)
+		ids := rids.Slice()
+		sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+		accepted[sid] = ids
 	}
 
 	return &DFA{
 		size:        stateSetDict.Size(),
 		states:      states,
-		intvs:       nfa.intvs,
+		alphabet:    nfa.alphabet,
 		trans:       trans,
 		initState:   initState,
+		initStates:  initStates,
 		finStates:   finStates,
 		stIDToRegID: stIDToRegID,
+		accepted:    accepted,
 	}
 }
 
-func (nfa *ImdNFA) SubsetConstruction() (states *StateSetDict[StateID], trans *ImdDFATransition, initState *StateSet, finStates *StateSetDict[Nothing]) {
+func (nfa *ImdNFA) eclosures() []*StateSet {
 	n := nfa.size
 	ecls := make([]*StateSet, n)
 	for sid := StateID(0); sid < StateID(n); sid++ {
 		ecls[sid] = nfa.Eclosure(sid)
 	}
 
-	initState = NewStateSet(n)
+	return ecls
+}
+
+// startSets returns the set of states for each start state.
+// When no start state is set, it is the union of epsilon closures of all initial states.
+func (nfa *ImdNFA) startSets(ecls []*StateSet) []*StateSet {
+	if len(nfa.starts) > 0 {
+		sets := make([]*StateSet, 0, len(nfa.starts))
+		for _, sid := range nfa.starts {
+			sets = append(sets, ecls[sid])
+		}
+		return sets
+	}
+
+	initState := NewStateSet(nfa.size)
 	iiter := nfa.initStates.iterator()
 	for iiter.HasNext() {
 		sid := iiter.Next()
 		initState = initState.Union(ecls[sid])
 	}
 
+	return []*StateSet{initState}
+}
+
+// SubsetConstruction converts the NFA to DFA. initState is the first start state.
+func (nfa *ImdNFA) SubsetConstruction() (states *StateSetDict[StateID], trans *ImdDFATransition, initState *StateSet, finStates *StateSetDict[Nothing]) {
+	n := nfa.size
+	ecls := nfa.eclosures()
+
 	visited := NewStateSetDict[StateID]() // key is set of states, value is state id for the key.
 	finStateDict := NewStateSetDict[Nothing]()
-	if initState.Intersection(nfa.finStates).IsAny() {
-		finStateDict.Set(initState, nothing)
-	}
-
 	delta := NewImdDFATransition()
 	deq := collection.NewDeque[*StateSet]()
-	deq.PushBack(initState)
+
+	startSets := nfa.startSets(ecls)
+	initState = startSets[0]
+	for _, ss := range startSets {
+		if ss.Intersection(nfa.finStates).IsAny() {
+			finStateDict.Set(ss, nothing)
+		}
+		deq.PushBack(ss)
+	}
 
 	id := StateID(0)
 	for deq.Size() > 0 {
@@ -135,8 +182,9 @@ func (nfa *ImdNFA) SubsetConstruction() (states *StateSetDict[StateID], trans *I
 		visited.Set(froms, id)
 		id++
 
-		// nfa.intvs is already disjoined.
-		for _, intv := range nfa.intvs {
+		// runes in a class have the same transitions, so one interval of it represents the class.
+		for cid := ClassID(0); cid < ClassID(nfa.alphabet.Size()); cid++ {
+			intv := nfa.alphabet.Intervals(cid)[0]
 			tos := NewStateSet(n)
 			fiter := froms.iterator()
 			for fiter.HasNext() {
@@ -157,7 +205,7 @@ func (nfa *ImdNFA) SubsetConstruction() (states *StateSetDict[StateID], trans *I
 				finStateDict.Set(tos, nothing)
 			}
 
-			delta.Set(froms, intv, tos)
+			delta.Set(froms, cid, tos)
 
 			if visited.Contains(tos) {
 				continue
@@ -210,27 +258,27 @@ func (nfa *ImdNFA) Eclosure(sid StateID) *StateSet {
 }
 
 type ImdDFATransition struct {
-	d *StateSetDict[map[Interval]*StateSet]
+	d *StateSetDict[map[ClassID]*StateSet]
 }
 
 func NewImdDFATransition() *ImdDFATransition {
 	return &ImdDFATransition{
-		d: NewStateSetDict[map[Interval]*StateSet](),
+		d: NewStateSetDict[map[ClassID]*StateSet](),
 	}
 }
 
-func (trans *ImdDFATransition) Set(from *StateSet, intv Interval, to *StateSet) {
+func (trans *ImdDFATransition) Set(from *StateSet, cid ClassID, to *StateSet) {
 	if v, ok := trans.d.Get(from); ok {
-		v[intv] = to
+		v[cid] = to
 		trans.d.Set(from, v)
 		return
 	}
 
-	mp := make(map[Interval]*StateSet)
-	mp[intv] = to
+	mp := make(map[ClassID]*StateSet)
+	mp[cid] = to
 	trans.d.Set(from, mp)
 }
 
-func (trans *ImdDFATransition) iterator() *stateSetDictIterator[map[Interval]*StateSet] {
+func (trans *ImdDFATransition) iterator() *stateSetDictIterator[map[ClassID]*StateSet] {
 	return trans.d.iterator()
 }
@@ -95,6 +95,7 @@ type NFA struct {
 	initStates   *collection.Set[StateID]
 	finStates    *collection.Set[StateID]
 	stIDToRegID  StateIDToRegexID
+	starts       []StateID
 }
 
 func NewNFA(states *collection.Set[StateID], etrans EpsilonTransition, trans NFATransition, initStates *collection.Set[StateID], finStates *collection.Set[StateID]) *NFA {
@@ -107,6 +108,18 @@ func NewNFA(states *collection.Set[StateID], etrans EpsilonTransition, trans NFA
 	}
 }
 
+func (nfa *NFA) GetInitStates() *collection.Set[StateID] {
+	return nfa.initStates
+}
+
+// SetStarts sets start states of the NFA.
+// The DFA converted from the NFA has a start state for each of them in the same order.
+// When no start state is set, the DFA has one start state made from all initial states.
+func (nfa *NFA) SetStarts(starts []StateID) *NFA {
+	nfa.starts = starts
+	return nfa
+}
+
 func (nfa *NFA) Sum(other *NFA) *NFA {
 	nfa.states = nfa.states.Union(other.states)
 	nfa.epsilonTrans.merge(other.epsilonTrans)
@@ -239,13 +252,19 @@ func (nfa *NFA) ToImdNFA() *ImdNFA {
 		stIDToRegID.Set(oldToNew[sid], rid)
 	}
 
+	starts := make([]StateID, 0, len(nfa.starts))
+	for _, sid := range nfa.starts {
+		starts = append(starts, oldToNew[sid])
+	}
+
 	return &ImdNFA{
 		size:        n,
-		intvs:       nfa.trans.intervals(),
+		alphabet:    newAlphabet(nfa.trans.intervals(), trans),
 		etrans:      NewImdEpsilonTransition(n, epsilonMap),
 		trans:       NewImdNFATransition(trans),
 		initStates:  initStates,
 		finStates:   finStates,
 		stIDToRegID: stIDToRegID,
+		starts:      starts,
 	}
 }
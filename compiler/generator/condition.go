@@ -0,0 +1,209 @@
+package generator
+
+import (
+	"bytes"
+	"fmt"
+	"go/token"
+	"strings"
+)
+
+const (
+	initialCondition = "INITIAL"
+	allConditions    = "*"
+)
+
+// Condition is a start condition declared by `%s` (inclusive) or `%x` (exclusive).
+// Rules without condition prefix are active in inclusive conditions.
+type Condition struct {
+	Name      string
+	Exclusive bool
+}
+
+// parseConditionLine parses `%s NAME...` or `%x NAME...` line.
+func parseConditionLine(line string) ([]Condition, error) {
+	fields := strings.Fields(line)
+	exclusive := fields[0] == "%x"
+	if len(fields) == 1 {
+		return nil, fmt.Errorf("%w: empty %v", ErrInvalidCondition, fields[0])
+	}
+
+	conds := make([]Condition, 0, len(fields)-1)
+	for _, name := range fields[1:] {
+		if !token.IsIdentifier(name) {
+			return nil, fmt.Errorf("%w: %q", ErrInvalidCondition, name)
+		}
+		conds = append(conds, Condition{Name: name, Exclusive: exclusive})
+	}
+
+	return conds, nil
+}
+
+func (spec *Spec) addConditions(pos Pos, conds []Condition) error {
+	for _, cond := range conds {
+		if cond.Name == initialCondition || spec.conditionID(cond.Name) >= 0 {
+			return fmt.Errorf("%w: duplicate %v", ErrInvalidCondition, cond.Name)
+		}
+		spec.Conditions = append(spec.Conditions, cond)
+		spec.conditionPos = append(spec.conditionPos, pos)
+	}
+
+	return nil
+}
+
+// conditions returns all start conditions. The first one is INITIAL.
+func (spec *Spec) conditions() []Condition {
+	return append([]Condition{{Name: initialCondition}}, spec.Conditions...)
+}
+
+// conditionPosOf returns the position where the condition is declared.
+// INITIAL is regarded as declared at the beginning of the rules section.
+func (spec *Spec) conditionPosOf(id int) Pos {
+	if id == 0 {
+		return spec.rulesPos
+	}
+
+	return spec.conditionPos[id-1]
+}
+
+// conditionID returns the index of the condition in spec.conditions(), or -1 if it is not declared.
+func (spec *Spec) conditionID(name string) int {
+	for i, cond := range spec.conditions() {
+		if cond.Name == name {
+			return i
+		}
+	}
+
+	return -1
+}
+
+// checkConditions reports undeclared conditions in rule prefixes.
+func (spec *Spec) checkConditions(rules []Rule) error {
+	for _, rule := range rules {
+		for _, name := range rule.Conditions {
+			if name != allConditions && spec.conditionID(name) < 0 {
+				return newSpecError(rule.Pos, fmt.Errorf("%w: %v", ErrUndeclaredCondition, name))
+			}
+		}
+	}
+
+	return nil
+}
+
+// activeIn reports whether the rule is active in the condition.
+func (r Rule) activeIn(cond Condition) bool {
+	if len(r.Conditions) == 0 {
+		return !cond.Exclusive
+	}
+	for _, name := range r.Conditions {
+		if name == allConditions || name == cond.Name {
+			return true
+		}
+	}
+
+	return false
+}
+
+// activeRules returns indices of rules active in each start condition.
+func (spec *Spec) activeRules() [][]int {
+	conds := spec.conditions()
+	active := make([][]int, len(conds))
+	for i, cond := range conds {
+		active[i] = make([]int, 0)
+		for j, rule := range spec.Rules {
+			if rule.activeIn(cond) {
+				active[i] = append(active[i], j)
+			}
+		}
+	}
+
+	return active
+}
+
+// eofRules returns the index of <<EOF>> rule for each start condition, or -1 if there is none.
+// A <<EOF>> rule without prefix applies to conditions which don't have their own one.
+func (spec *Spec) eofRules() ([]int, error) {
+	conds := spec.conditions()
+	ids := make([]int, len(conds))
+	for i := range ids {
+		ids[i] = -1
+	}
+
+	fallback := -1
+	for j, rule := range spec.EOFRules {
+		if len(rule.Conditions) == 0 {
+			if fallback >= 0 {
+				return nil, newSpecError(rule.Pos, fmt.Errorf("%w: multiple %v rules", ErrInvalidRule, eofRule))
+			}
+			fallback = j
+			continue
+		}
+		for i, cond := range conds {
+			if !rule.activeIn(cond) {
+				continue
+			}
+			if ids[i] >= 0 {
+				return nil, newSpecError(rule.Pos, fmt.Errorf("%w: multiple %v rules for %v", ErrInvalidRule, eofRule, cond.Name))
+			}
+			ids[i] = j
+		}
+	}
+	for i := range ids {
+		if ids[i] < 0 {
+			ids[i] = fallback
+		}
+	}
+
+	return ids, nil
+}
+
+// readConditions reads `<NAME1,NAME2>` prefix of a rule if exists.
+func readConditions(buf *bytes.Buffer) []string {
+	b := buf.Bytes()
+	if len(b) < 2 || b[0] != '<' || b[1] == '<' {
+		return nil
+	}
+	end := bytes.IndexByte(b, '>')
+	if end < 0 {
+		return nil
+	}
+	names := strings.Split(string(b[1:end]), ",")
+	for i, name := range names {
+		name = strings.TrimSpace(name)
+		if name != allConditions && !token.IsIdentifier(name) {
+			return nil
+		}
+		names[i] = name
+	}
+	buf.Next(end + 1)
+
+	return names
+}
+
+// genConditions returns the names of start conditions and their constants such as INITIAL,
+// which are WordINITIAL with prefix=word.
+func genConditions(cfg Config, conds []Condition) string {
+	var buf bytes.Buffer
+	buf.WriteString("// names of start conditions\n")
+	buf.WriteString(fmt.Sprintf("var %vConditionNames = [...]string{\n", cfg.Prefix))
+	for _, cond := range conds {
+		buf.WriteString(fmt.Sprintf("%q,\n", cond.Name))
+	}
+	buf.WriteString("}\n\n")
+	if len(conds) == 1 {
+		return buf.String()
+	}
+
+	// constants have the public prefix so that lexers of different prefixes can declare the same conditions.
+	buf.WriteString("// start conditions\n")
+	buf.WriteString("const (\n")
+	for i, cond := range conds {
+		if i == 0 {
+			buf.WriteString(fmt.Sprintf("%v%v = iota\n", cfg.PublicPrefix(), cond.Name))
+		} else {
+			buf.WriteString(cfg.PublicPrefix() + cond.Name + "\n")
+		}
+	}
+	buf.WriteString(")\n")
+
+	return buf.String()
+}
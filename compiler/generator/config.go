@@ -0,0 +1,287 @@
+package generator
+
+import (
+	"fmt"
+	"go/token"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+const (
+	// defaultPrefix is the prefix of identifiers of the generated file by default.
+	defaultPrefix = "yy"
+
+	TableFormatMap    = "map"
+	TableFormatSorted = "sorted"
+	TableFormatClass  = "class"
+
+	BackendTable = "table"
+	BackendGoto  = "goto"
+
+	InputReader         = "reader"
+	InputRuneReadSeeker = "runereadseeker"
+
+	RecoverRune = "rune"
+	RecoverSync = "sync"
+	RecoverEcho = "echo"
+)
+
+// Config holds the settings of a generated lexer.
+// It is filled by `%option` lines of a lexer configuration file and
+// options given from the command line override them.
+type Config struct {
+	PackageName   string
+	Prefix        string // prefix of the lexer type name and package level identifiers
+	Caseless      bool
+	Yylineno      bool
+	Debug         bool
+	Line          bool   // emit `//line` directives pointing the lexer configuration file
+	GlobalText    bool   // emit package level YYText variable which is shared by all lexers
+	DisplayColumn bool   // track display columns by East Asian Width and emit RuneWidth and Caret
+	Iter          bool   // emit All iterator in the file with go1.23 build constraint next to the lexer file
+	Backend       string // table: table driven DFA, goto: DFA states are labeled blocks jumping by goto
+	TableFormat   string // format of the transition table of the table backend
+	Input         string
+	Recover       string // how a rune no rule matches is skipped. rune: the rune, sync: runes until SyncRunes, echo: written to Out
+	SyncRunes     string // synchronizing runes of recover=sync
+	CollectErrors bool   // append scan errors to Errors of the lexer and continue instead of returning them
+}
+
+func NewConfig() Config {
+	return Config{
+		PackageName:   "main",
+		Prefix:        defaultPrefix,
+		Caseless:      false,
+		Yylineno:      false,
+		Debug:         false,
+		Line:          true,
+		GlobalText:    false,
+		DisplayColumn: false,
+		Iter:          false,
+		Backend:       BackendTable,
+		TableFormat:   TableFormatClass,
+		Input:         InputReader,
+		Recover:       RecoverRune,
+		SyncRunes:     "\n",
+		CollectErrors: false,
+	}
+}
+
+// LexerName returns the type name of the generated lexer.
+func (cfg Config) LexerName() string {
+	return cfg.Prefix + "Lexer"
+}
+
+// PublicPrefix returns the prefix of exported identifiers such as the constructor New and TokenKind.
+// It is empty for the default prefix, and the prefix beginning with upper case letter otherwise,
+// so that lexers of different prefixes can live in one package.
+func (cfg Config) PublicPrefix() string {
+	if cfg.Prefix == defaultPrefix {
+		return ""
+	}
+
+	return upperFirst(cfg.Prefix)
+}
+
+// UpperPrefix returns the prefix of exported variables such as ErrYYScan and YYText.
+func (cfg Config) UpperPrefix() string {
+	if cfg.Prefix == defaultPrefix {
+		return "YY"
+	}
+
+	return upperFirst(cfg.Prefix)
+}
+
+// InputType returns the parameter type of New and PushInput.
+// Both inputs are read through the buffer of the lexer, and RuneReadSeeker is kept for compatibility.
+func (cfg Config) InputType() string {
+	if cfg.Input == InputRuneReadSeeker {
+		return cfg.PublicPrefix() + "RuneReadSeeker"
+	}
+
+	return "io.Reader"
+}
+
+func upperFirst(s string) string {
+	r, size := utf8.DecodeRuneInString(s)
+
+	return string(unicode.ToUpper(r)) + s[size:]
+}
+
+// Option is a pair of option name and its value.
+// `%option caseless` is Option{Name: "caseless"} and
+// `%option package=foo` is Option{Name: "package", Value: "foo"}.
+type Option struct {
+	Name  string
+	Value string
+}
+
+// ParseOption parses `name`, `name=value` or `name="value"`.
+func ParseOption(s string) (Option, error) {
+	name, value, found := strings.Cut(s, "=")
+	if name == "" || (found && value == "") {
+		return Option{}, fmt.Errorf("%w: %q", ErrInvalidOption, s)
+	}
+	if strings.HasPrefix(value, `"`) {
+		v, err := strconv.Unquote(value)
+		if err != nil {
+			return Option{}, fmt.Errorf("%w: %q", ErrInvalidOption, s)
+		}
+		value = v
+	}
+
+	return Option{Name: name, Value: value}, nil
+}
+
+// parseOptionLine parses `%option` line. A line can have several options.
+func parseOptionLine(line string) ([]Option, error) {
+	fields := splitOptions(strings.TrimPrefix(line, "%option"))
+	if len(fields) == 0 {
+		return nil, fmt.Errorf("%w: empty %%option", ErrInvalidOption)
+	}
+
+	opts := make([]Option, 0, len(fields))
+	for _, f := range fields {
+		opt, err := ParseOption(f)
+		if err != nil {
+			return nil, err
+		}
+		opts = append(opts, opt)
+	}
+
+	return opts, nil
+}
+
+// splitOptions splits s at spaces out of double quoted values, so that `sync=" ;"` is one option.
+// Backslash escapes the next rune in quotes as Go string literals.
+func splitOptions(s string) []string {
+	fields := make([]string, 0)
+	var field strings.Builder
+	quoted, escaped := false, false
+	for _, r := range s {
+		switch {
+		case escaped:
+			escaped = false
+		case quoted && r == '\\':
+			escaped = true
+		case r == '"':
+			quoted = !quoted
+		case !quoted && unicode.IsSpace(r):
+			if field.Len() > 0 {
+				fields = append(fields, field.String())
+				field.Reset()
+			}
+			continue
+		}
+		field.WriteRune(r)
+	}
+	if field.Len() > 0 {
+		fields = append(fields, field.String())
+	}
+
+	return fields
+}
+
+func (cfg *Config) Apply(opts ...Option) error {
+	for _, opt := range opts {
+		if err := cfg.Set(opt.Name, opt.Value); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Set sets the option. Boolean options accept `noname` form to disable it.
+func (cfg *Config) Set(name, value string) error {
+	switch name {
+	case "package":
+		if !token.IsIdentifier(value) {
+			return fmt.Errorf("%w: package=%q", ErrInvalidOption, value)
+		}
+		cfg.PackageName = value
+	case "prefix":
+		if !token.IsIdentifier(value) {
+			return fmt.Errorf("%w: prefix=%q", ErrInvalidOption, value)
+		}
+		cfg.Prefix = value
+	case "backend":
+		switch value {
+		case BackendTable, BackendGoto:
+		default:
+			return fmt.Errorf("%w: backend=%q", ErrInvalidOption, value)
+		}
+		cfg.Backend = value
+	case "table":
+		switch value {
+		case TableFormatMap, TableFormatSorted, TableFormatClass:
+		default:
+			return fmt.Errorf("%w: table=%q", ErrInvalidOption, value)
+		}
+		cfg.TableFormat = value
+	case "input":
+		switch value {
+		case InputReader, InputRuneReadSeeker:
+		default:
+			return fmt.Errorf("%w: input=%q", ErrInvalidOption, value)
+		}
+		cfg.Input = value
+	case "recover":
+		switch value {
+		case RecoverRune, RecoverSync, RecoverEcho:
+		default:
+			return fmt.Errorf("%w: recover=%q", ErrInvalidOption, value)
+		}
+		cfg.Recover = value
+	case "sync":
+		if value == "" {
+			return fmt.Errorf("%w: sync=%q", ErrInvalidOption, value)
+		}
+		cfg.SyncRunes = value
+	default:
+		return cfg.setBool(name, value)
+	}
+
+	return nil
+}
+
+func (cfg *Config) setBool(name, value string) error {
+	b := true
+	if value != "" {
+		v, err := strconv.ParseBool(value)
+		if err != nil {
+			return fmt.Errorf("%w: %v=%q", ErrInvalidOption, name, value)
+		}
+		b = v
+	}
+
+	var p *bool
+	switch name {
+	case "caseless", "nocaseless":
+		p = &cfg.Caseless
+	case "yylineno", "noyylineno":
+		p = &cfg.Yylineno
+	case "debug", "nodebug":
+		p = &cfg.Debug
+	case "line", "noline":
+		p = &cfg.Line
+	case "globaltext", "noglobaltext":
+		p = &cfg.GlobalText
+	case "displaycolumn", "nodisplaycolumn":
+		p = &cfg.DisplayColumn
+	case "iter", "noiter":
+		p = &cfg.Iter
+	case "collecterrors", "nocollecterrors":
+		p = &cfg.CollectErrors
+	default:
+		return fmt.Errorf("%w: %v", ErrUnknownOption, name)
+	}
+	if strings.HasPrefix(name, "no") {
+		b = !b
+	}
+	*p = b
+
+	return nil
+}
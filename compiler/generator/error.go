@@ -0,0 +1,38 @@
+package generator
+
+import (
+	"errors"
+	"fmt"
+)
+
+var (
+	ErrUnknownOption       = errors.New("unknown option")
+	ErrInvalidOption       = errors.New("invalid option")
+	ErrUnknownDirective    = errors.New("unknown directive")
+	ErrInvalidToken        = errors.New("invalid token declaration")
+	ErrDuplicateToken      = errors.New("duplicate token declaration")
+	ErrUndeclaredToken     = errors.New("undeclared token")
+	ErrInvalidRule         = errors.New("invalid rule")
+	ErrInvalidInclude      = errors.New("invalid include")
+	ErrIncludeCycle        = errors.New("include cycle")
+	ErrInvalidCondition    = errors.New("invalid start condition")
+	ErrUndeclaredCondition = errors.New("undeclared start condition")
+)
+
+// SpecError is an error at a position of the lexer configuration file.
+type SpecError struct {
+	Pos Pos
+	Err error
+}
+
+func newSpecError(pos Pos, err error) *SpecError {
+	return &SpecError{Pos: pos, Err: err}
+}
+
+func (e *SpecError) Error() string {
+	return fmt.Sprintf("%v: %v", e.Pos, e.Err)
+}
+
+func (e *SpecError) Unwrap() error {
+	return e.Err
+}
@@ -1,3 +1,24 @@
 package generator
 
-var LexerNFA = lexerNFA
+import "github.com/goropikari/tlex/automata"
+
+func LexerNFA(regexs []string) *automata.NFA {
+	return lexerNFA(regexs, allActive(regexs), false)
+}
+
+func LexerCaselessNFA(regexs []string) *automata.NFA {
+	return lexerNFA(regexs, allActive(regexs), true)
+}
+
+// LexerConditionNFA returns NFA whose c-th start state accepts regexs[i] for i in active[c].
+func LexerConditionNFA(regexs []string, active [][]int) *automata.NFA {
+	return lexerNFA(regexs, active, false)
+}
+
+func allActive(regexs []string) [][]int {
+	ids := make([]int, 0, len(regexs))
+	for i := range regexs {
+		ids = append(ids, i)
+	}
+	return [][]int{ids}
+}
@@ -4,46 +4,114 @@ import (
 	"bufio"
 	"bytes"
 	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
 	"io"
-	"log"
 	"os"
+	"sort"
+	"strconv"
+	"strings"
 	"text/template"
+	"unicode"
+	"unicode/utf8"
 
 	"github.com/goropikari/tlex/automata"
 	"github.com/goropikari/tlex/collection"
 	"github.com/goropikari/tlex/compiler/regexp"
+	"github.com/goropikari/tlex/math"
 	"golang.org/x/tools/imports"
 )
 
 type LexerTemplate struct {
-	PackageName          string
+	Config
+	KindType             string
+	TokenKindTmpl        string
+	ConditionsTmpl       string
+	StartStatesTmpl      string
 	EmbeddedTmpl         string
 	StateIDToRegexIDTmpl string
 	FinStatesTmpl        string
 	TransitionTableTmpl  string
+	TransitionOffsetTmpl string
+	ByteClassesTmpl      string
+	ClassRangesTmpl      string
+	NumClasses           int
+	DirectScanTmpl       string
 	RegexActionsTmpl     string
+	EOFActionTmpl        string
+	EOFActionTerminates  bool
 	UserCodeTmpl         string
 }
 
-func Generate(r *bufio.Reader, pkgName string, outfile string) {
-	// parse lexer configuration
-	parser := NewParser(r)
-	def, rules, userCode := parser.Parse()
+// Generate generates a lexer from the lexer configuration read from r.
+// overrides are applied after `%option` of the configuration.
+// %include is resolved relative to the current directory.
+func Generate(r *bufio.Reader, outfile string, overrides ...Option) error {
+	spec, err := NewParser(r).Parse()
+	if err != nil {
+		return err
+	}
+	if err := spec.Config.Apply(overrides...); err != nil {
+		return err
+	}
+
+	return GenerateSpec(spec, outfile)
+}
+
+// GenerateFile generates a lexer from the lexer configuration file.
+// %include is resolved relative to the including file.
+func GenerateFile(srcfile string, outfile string, overrides ...Option) error {
+	spec, err := ParseFile(srcfile, overrides...)
+	if err != nil {
+		return err
+	}
+
+	return GenerateSpec(spec, outfile)
+}
+
+// ParseFile parses the lexer configuration file and applies overrides to its options.
+func ParseFile(srcfile string, overrides ...Option) (*Spec, error) {
+	f, err := os.Open(srcfile)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	spec, err := NewParser(bufio.NewReader(f)).SetFilename(srcfile).Parse()
+	if err != nil {
+		return nil, err
+	}
+	if err := spec.Config.Apply(overrides...); err != nil {
+		return nil, err
+	}
+
+	return spec, nil
+}
+
+// GenerateSpec generates a lexer from the parsed lexer configuration,
+// so that the spec can be shared with FindShadowedRules and FindOverlaps.
+func GenerateSpec(spec *Spec, outfile string) error {
+	cfg := spec.Config
 
 	// compile regex and generate DFA
 	regexs := make([]string, 0)
-	actions := make([]string, 0)
-	for _, v := range rules {
-		regexs = append(regexs, v[0])
-		actions = append(actions, v[1])
+	for _, v := range spec.Rules {
+		regexs = append(regexs, v.Regex)
+	}
+	if err := checkActionTokens(spec); err != nil {
+		return err
+	}
+	eofRules, err := spec.eofRules()
+	if err != nil {
+		return err
 	}
-	dfa := lexerDFA(regexs)
+	dfa := lexerDFA(regexs, spec.activeRules(), cfg.Caseless)
 	oldstIDToNewStID := make(map[automata.StateID]automata.StateID)
 	id := automata.StateID(1) // state id = 0 is reserved for dead state.
-	oldstIDToNewStID[dfa.GetInitState()] = automata.StateID(id)
-	id++
-	for _, st := range dfa.GetStates() {
-		if st == dfa.GetInitState() {
+	// start states of conditions come first.
+	for _, st := range stateOrder(dfa) {
+		if _, ok := oldstIDToNewStID[st]; ok {
 			continue
 		}
 		oldstIDToNewStID[st] = id
@@ -57,43 +125,125 @@ func Generate(r *bufio.Reader, pkgName string, outfile string) {
 	}
 
 	// generate lexer file
-	embeddedTmpl := def
+	lw := newLineWriter(cfg, outfile)
+	embeddedTmpl := lw.wrapLines(spec.Definition, spec.definitionOrigins)
 	stateIDToRegexIDTmpl := genStIdToRegexID(idToRegexID)
-	finStatesTmpl := genFinStates(newStIDToOldStID, dfa.GetFinStates())
-	transitionTableTmpl := genTransitionTable(oldstIDToNewStID, newStIDToOldStID, dfa.GetTransitionTable())
-	regexActionsTmpl := genRegexActions(actions)
-	userCodeTmpl := userCode
+	var finStatesTmpl, transitionTableTmpl, transitionOffsetTmpl, byteClassesTmpl, classRangesTmpl string
+	switch cfg.TableFormat {
+	case TableFormatMap:
+		finStatesTmpl = genFinStates(newStIDToOldStID, dfa.GetFinStates())
+		transitionTableTmpl = genTransitionTable(cfg, oldstIDToNewStID, newStIDToOldStID, dfa.GetTransitionTable())
+	case TableFormatSorted:
+		finStatesTmpl = genFinStateFlags(newStIDToOldStID, dfa.GetFinStates())
+		transitionTableTmpl, transitionOffsetTmpl = genSortedTransitions(oldstIDToNewStID, newStIDToOldStID, dfa.GetTransitionTable())
+	default:
+		finStatesTmpl = genFinStateFlags(newStIDToOldStID, dfa.GetFinStates())
+		byteClassesTmpl, classRangesTmpl = genClasses(dfa.GetAlphabet())
+		transitionTableTmpl = genClassTransitions(oldstIDToNewStID, newStIDToOldStID, dfa.GetTransitionTable())
+	}
+	var directScanTmpl string
+	if cfg.Backend == BackendGoto {
+		directScanTmpl = genDirectScan(cfg, oldstIDToNewStID, newStIDToOldStID, dfa)
+	}
+	regexActionsTmpl := genRegexActions(lw, spec.Rules)
+	eofActionTmpl, eofActionTerminates := genEOFAction(lw, spec.EOFRules, eofRules)
+	userCodeTmpl := lw.wrap(spec.UserCode, spec.userCodePos)
 
 	lexCfg := LexerTemplate{
-		PackageName:          pkgName,
+		Config:               cfg,
+		KindType:             spec.kindType(),
+		TokenKindTmpl:        genTokenKinds(cfg, spec.Tokens),
+		ConditionsTmpl:       genConditions(cfg, spec.conditions()),
+		StartStatesTmpl:      genStartStates(oldstIDToNewStID, dfa.GetInitStates()),
 		EmbeddedTmpl:         embeddedTmpl,
 		StateIDToRegexIDTmpl: stateIDToRegexIDTmpl,
 		FinStatesTmpl:        finStatesTmpl,
 		TransitionTableTmpl:  transitionTableTmpl,
+		TransitionOffsetTmpl: transitionOffsetTmpl,
+		ByteClassesTmpl:      byteClassesTmpl,
+		ClassRangesTmpl:      classRangesTmpl,
+		NumClasses:           dfa.GetAlphabet().Size() + 1,
+		DirectScanTmpl:       directScanTmpl,
 		RegexActionsTmpl:     regexActionsTmpl,
+		EOFActionTmpl:        eofActionTmpl,
+		EOFActionTerminates:  eofActionTerminates,
 		UserCodeTmpl:         userCodeTmpl,
 	}
 	s := tmpl
 	t := template.Must(template.New("lexer").Parse(s))
+	template.Must(t.New("width").Parse(widthTmpl))
 
 	var buf bytes.Buffer
 	if err := t.Execute(&buf, lexCfg); err != nil {
-		panic(err)
+		return err
 	}
 	// t.Execute(os.Stdout, lexCfg)
 
 	f, err := os.OpenFile(outfile, os.O_RDWR|os.O_CREATE|os.O_TRUNC, 0644)
 	if err != nil {
-		log.Fatal(err)
+		return err
 	}
 	data, err := imports.Process(outfile, buf.Bytes(), nil)
 	if err != nil {
-		panic(err)
+		f.Close()
+		return err
+	}
+	data = resolveLineDirectives(data, outfile)
+	if _, err := io.Copy(f, bytes.NewReader(data)); err != nil {
+		f.Close()
+		return err
 	}
-	io.Copy(f, bytes.NewReader(data))
 	if err := f.Close(); err != nil {
-		log.Fatal(err)
+		return err
 	}
+	if !cfg.Iter {
+		return nil
+	}
+
+	return generateIter(lexCfg, outfile)
+}
+
+// generateIter writes the iterator file next to the lexer file outfile.
+func generateIter(lexCfg LexerTemplate, outfile string) error {
+	t := template.Must(template.New("iter").Parse(iterTmpl))
+	var buf bytes.Buffer
+	if err := t.Execute(&buf, lexCfg); err != nil {
+		return err
+	}
+
+	return os.WriteFile(iterFilename(outfile), buf.Bytes(), 0644)
+}
+
+// stateOrder returns states in breadth first order from the start states,
+// so that generated files are reproducible.
+func stateOrder(dfa *automata.DFA) []automata.StateID {
+	trans := dfa.GetTransitionTable()
+	visited := make(map[automata.StateID]bool)
+	order := make([]automata.StateID, 0)
+	for _, st := range dfa.GetInitStates() {
+		if !visited[st] {
+			visited[st] = true
+			order = append(order, st)
+		}
+	}
+	for i := 0; i < len(order); i++ {
+		for _, intv := range trans.Intervals(order[i]) {
+			to, _ := trans.Get(order[i], intv)
+			if !visited[to] {
+				visited[to] = true
+				order = append(order, to)
+			}
+		}
+	}
+	rest := make([]automata.StateID, 0)
+	for _, st := range dfa.GetStates() {
+		if !visited[st] {
+			rest = append(rest, st)
+		}
+	}
+	sort.Slice(rest, func(i, j int) bool { return rest[i] < rest[j] })
+
+	return append(order, rest...)
 }
 
 func genStIdToRegexID(idToRegexID []automata.RegexID) string {
@@ -116,7 +266,7 @@ func genFinStates(newStIDToOldStID []automata.StateID, finStates *collection.Set
 	return buf.String()
 }
 
-func genTransitionTable(oldIDToNewID map[automata.StateID]automata.StateID, newIDToOldID []automata.StateID, delta *automata.DFATransition) string {
+func genTransitionTable(cfg Config, oldIDToNewID map[automata.StateID]automata.StateID, newIDToOldID []automata.StateID, delta *automata.DFATransition) string {
 	var buf bytes.Buffer
 	for fromID := automata.StateID(1); fromID <= automata.StateID(len(oldIDToNewID)); fromID++ {
 		mp, ok := delta.GetMap(newIDToOldID[fromID])
@@ -126,7 +276,7 @@ func genTransitionTable(oldIDToNewID map[automata.StateID]automata.StateID, newI
 		buf.WriteString(fmt.Sprintf("%v: {\n", fromID))
 		for intv, oldtoID := range mp {
 			toID := oldIDToNewID[oldtoID]
-			buf.WriteString(fmt.Sprintf("yyinterval{l: %v, r: %v}: %v,\n", intv.L, intv.R, toID))
+			buf.WriteString(fmt.Sprintf("%vinterval{l: %v, r: %v}: %v,\n", cfg.Prefix, intv.L, intv.R, toID))
 		}
 		buf.WriteString("},\n")
 	}
@@ -134,46 +284,343 @@ func genTransitionTable(oldIDToNewID map[automata.StateID]automata.StateID, newI
 	return buf.String()
 }
 
-func lexerNFA(regexs []string) *automata.NFA {
+// genFinStateFlags generates whether each state is final. The first one is the dead state.
+func genFinStateFlags(newStIDToOldStID []automata.StateID, finStates *collection.Set[automata.StateID]) string {
+	var buf bytes.Buffer
+	buf.WriteString("false,\n")
+	for _, st := range newStIDToOldStID[1:] {
+		buf.WriteString(fmt.Sprintf("%v,\n", finStates.Contains(st)))
+	}
+
+	return buf.String()
+}
+
+// genSortedTransitions generates transitions of all states in a flat array.
+// Transitions of state s are transitions[offsets[s]:offsets[s+1]] sorted by the interval.
+func genSortedTransitions(oldIDToNewID map[automata.StateID]automata.StateID, newIDToOldID []automata.StateID, delta *automata.DFATransition) (transitions string, offsets string) {
+	var tbuf, obuf bytes.Buffer
+	n := 0
+	obuf.WriteString("0, 0, // state 0 is dead state\n")
+	for fromID := 1; fromID < len(newIDToOldID); fromID++ {
+		from := newIDToOldID[fromID]
+		for _, intv := range delta.Intervals(from) {
+			to, _ := delta.Get(from, intv)
+			tbuf.WriteString(fmt.Sprintf("{%v, %v, %v},\n", intv.L, intv.R, oldIDToNewID[to]))
+			n++
+		}
+		obuf.WriteString(fmt.Sprintf("%v,\n", n))
+	}
+
+	return tbuf.String(), obuf.String()
+}
+
+// byteClassLimit is the number of runes whose classes are looked up by a dense table, i.e. ASCII and Latin-1.
+const byteClassLimit = 256
+
+// genClasses generates the dense class table of runes less than byteClassLimit and
+// the sorted class ranges of the other runes.
+// Class ids in the generated code are shifted by one, and class 0 is runes without transitions.
+func genClasses(alpha *automata.Alphabet) (byteClasses string, classRanges string) {
+	classes := make([]int, byteClassLimit)
+	var rbuf bytes.Buffer
+	for _, rng := range alpha.Ranges() {
+		for r := rng.L; r <= rng.R && r < byteClassLimit; r++ {
+			classes[r] = int(rng.Class) + 1
+		}
+		if rng.R < byteClassLimit {
+			continue
+		}
+		rbuf.WriteString(fmt.Sprintf("{%v, %v, %v},\n", math.Max(rng.L, byteClassLimit), rng.R, int(rng.Class)+1))
+	}
+
+	var bbuf bytes.Buffer
+	for i, c := range classes {
+		bbuf.WriteString(fmt.Sprintf("%v,", c))
+		if i%16 == 15 {
+			bbuf.WriteString("\n")
+		} else {
+			bbuf.WriteString(" ")
+		}
+	}
+
+	return bbuf.String(), rbuf.String()
+}
+
+// genClassTransitions generates the dense transition table indexed by state id and class id.
+// Each line is transitions of a state.
+func genClassTransitions(oldIDToNewID map[automata.StateID]automata.StateID, newIDToOldID []automata.StateID, delta *automata.DFATransition) string {
+	n := delta.Alphabet().Size() + 1
+	var buf bytes.Buffer
+	buf.WriteString(strings.Repeat("0, ", n) + "// state 0 is dead state\n")
+	for fromID := 1; fromID < len(newIDToOldID); fromID++ {
+		from := newIDToOldID[fromID]
+		for cid := automata.ClassID(0); cid < automata.ClassID(n); cid++ {
+			to := automata.StateID(0)
+			if cid > 0 {
+				if sid, ok := delta.GetClass(from, cid-1); ok {
+					to = oldIDToNewID[sid]
+				}
+			}
+			buf.WriteString(fmt.Sprintf("%v, ", to))
+		}
+		buf.WriteString("\n")
+	}
+
+	return buf.String()
+}
+
+// genDirectScan generates the body of scan of the goto backend.
+// The block labeled yyS<id> reads a rune in the state id and jumps to the next state.
+// The block labeled yyA<id> accepts the rune moving to the state id and falls into yyS<id>.
+// Start states are entered from yyS<id> since no rune has been read yet.
+func genDirectScan(cfg Config, oldIDToNewID map[automata.StateID]automata.StateID, newIDToOldID []automata.StateID, dfa *automata.DFA) string {
+	delta := dfa.GetTransitionTable()
+	isStart := make(map[automata.StateID]bool)
+	var buf bytes.Buffer
+	buf.WriteString("switch yylex.currStateID {\n")
+	for _, st := range dfa.GetInitStates() {
+		id := oldIDToNewID[st]
+		if isStart[id] {
+			continue
+		}
+		isStart[id] = true
+		buf.WriteString(fmt.Sprintf("case %v:\ngoto yyS%v\n", id, id))
+	}
+	buf.WriteString(fmt.Sprintf("}\nreturn false, Err%vScan\n", cfg.UpperPrefix()))
+
+	isTarget := make(map[automata.StateID]bool)
+	for _, from := range newIDToOldID[1:] {
+		for _, intv := range delta.Intervals(from) {
+			to, _ := delta.Get(from, intv)
+			isTarget[oldIDToNewID[to]] = true
+		}
+	}
+
+	for id := 1; id < len(newIDToOldID); id++ {
+		sid := automata.StateID(id)
+		from := newIDToOldID[id]
+		buf.WriteString("\n")
+		if isTarget[sid] {
+			buf.WriteString(fmt.Sprintf("yyA%v:\nyylex.currPos += yysize\n", id))
+			if dfa.GetFinStates().Contains(from) {
+				buf.WriteString(fmt.Sprintf("yylex.finPos = yylex.currPos\nyylex.finRegexID = %v\n", dfa.GetRegexID(from)))
+			}
+		}
+		if isStart[sid] {
+			buf.WriteString(fmt.Sprintf("yyS%v:\n", id))
+		}
+
+		intvs := delta.Intervals(from)
+		if len(intvs) == 0 {
+			if isStart[sid] {
+				// the start state of a condition without rules still reports the end of the input.
+				buf.WriteString("if yyr, yysize, err = yylex.currRune(); err != nil {\nreturn yylex.scanEnd(err)\n}\n")
+			}
+			buf.WriteString("return true, nil\n")
+			continue
+		}
+
+		// adjacent intervals to the same state are merged, and
+		// conditions are grouped by the next state in the order of runes.
+		tos := make([]automata.StateID, 0)
+		ranges := make(map[automata.StateID][]automata.Interval)
+		for _, intv := range intvs {
+			to, _ := delta.Get(from, intv)
+			to = oldIDToNewID[to]
+			rs, ok := ranges[to]
+			if !ok {
+				tos = append(tos, to)
+			}
+			if n := len(rs); n > 0 && rs[n-1].R+1 == intv.L {
+				rs[n-1].R = intv.R
+				continue
+			}
+			ranges[to] = append(rs, intv)
+		}
+		buf.WriteString("if yyr, yysize, err = yylex.currRune(); err != nil {\nreturn yylex.scanEnd(err)\n}\n")
+		buf.WriteString("switch {\n")
+		for _, to := range tos {
+			conds := make([]string, 0, len(ranges[to]))
+			for _, intv := range ranges[to] {
+				conds = append(conds, runeCondition(intv))
+			}
+			buf.WriteString(fmt.Sprintf("case %v:\ngoto yyA%v\n", strings.Join(conds, ", "), to))
+		}
+		buf.WriteString("}\nreturn true, nil\n")
+	}
+
+	return buf.String()
+}
+
+// runeCondition returns the condition that yyr is in intv.
+func runeCondition(intv automata.Interval) string {
+	switch {
+	case intv.L == intv.R:
+		return fmt.Sprintf("yyr == %v", runeLiteral(intv.L))
+	case intv.L == 0:
+		return fmt.Sprintf("yyr <= %v", runeLiteral(intv.R))
+	case intv.R == unicode.MaxRune:
+		return fmt.Sprintf("%v <= yyr", runeLiteral(intv.L))
+	default:
+		return fmt.Sprintf("%v <= yyr && yyr <= %v", runeLiteral(intv.L), runeLiteral(intv.R))
+	}
+}
+
+// runeLiteral returns the printable ASCII rune as a quoted literal and others as a number.
+func runeLiteral(r int) string {
+	if r < utf8.RuneSelf && unicode.IsPrint(rune(r)) {
+		return strconv.QuoteRune(rune(r))
+	}
+
+	return strconv.Itoa(r)
+}
+
+func genStartStates(oldIDToNewID map[automata.StateID]automata.StateID, initStates []automata.StateID) string {
+	var buf bytes.Buffer
+	for _, st := range initStates {
+		buf.WriteString(fmt.Sprintf("%v,\n", oldIDToNewID[st]))
+	}
+
+	return buf.String()
+}
+
+// lexerNFA builds the NFA of the lexer.
+// active[c] is indices of regexs which are active in c-th start condition,
+// and the NFA has a start state for each condition.
+func lexerNFA(regexs []string, active [][]int, caseless bool) *automata.NFA {
 	nfas := make([]*automata.NFA, 0)
 	for i, regex := range regexs {
-		nfa := parse(regex)
+		nfa := parse(regex, caseless)
 		nfa.SetRegexID(automata.RegexID(i + 1))
 		nfas = append(nfas, nfa)
 	}
 
+	starts := make([]automata.StateID, 0, len(active))
+	startNFAs := make([]*automata.NFA, 0, len(active))
+	for _, ids := range active {
+		sid := automata.NewStateID()
+		etrans := automata.NewEpsilonTransition()
+		for _, i := range ids {
+			iter := nfas[i].GetInitStates().Iterator()
+			for iter.HasNext() {
+				etrans.Set(sid, iter.Next())
+			}
+		}
+		startNFAs = append(startNFAs, automata.NewNFA(
+			collection.NewSet[automata.StateID]().Insert(sid),
+			etrans,
+			automata.NewNFATransition(),
+			collection.NewSet[automata.StateID]().Insert(sid),
+			collection.NewSet[automata.StateID](),
+		))
+		starts = append(starts, sid)
+	}
+
 	nfa := nfas[0]
-	for _, n := range nfas[1:] {
+	for _, n := range append(nfas[1:], startNFAs...) {
 		nfa = nfa.Sum(n)
 	}
 
-	return nfa
+	return nfa.SetStarts(starts)
 }
 
-func lexerDFA(regexs []string) *automata.DFA {
-	nfa := lexerNFA(regexs)
+func lexerDFA(regexs []string, active [][]int, caseless bool) *automata.DFA {
+	nfa := lexerNFA(regexs, active, caseless)
 
 	return nfa.ToImdNFA().ToDFA().LexerMinimize()
 }
 
-func genRegexActions(actions []string) string {
-
+func genRegexActions(lw lineWriter, rules []Rule) string {
 	var buf bytes.Buffer
-	for i, v := range actions {
+	for i, v := range rules {
 		buf.WriteString(fmt.Sprintf("case %v:\n", i+1))
-		buf.WriteString(v + "\n")
-		buf.WriteString("goto yystart\n")
+		action, terminates := genAction(lw, v)
+		buf.WriteString(action)
+		if !terminates {
+			buf.WriteString("goto yystart\n")
+		}
 	}
 
 	return buf.String()
 }
 
-func parse(regex string) *automata.NFA {
+// genEOFAction generates actions of <<EOF>> rules. eofRules[c] is the index of the rule for c-th condition.
+func genEOFAction(lw lineWriter, rules []Rule, eofRules []int) (action string, terminates bool) {
+	if len(rules) == 0 {
+		return "", false
+	}
+	if len(rules) == 1 && len(rules[0].Conditions) == 0 {
+		return genAction(lw, rules[0])
+	}
+
+	var buf bytes.Buffer
+	buf.WriteString("switch yylex.cond {\n")
+	for j, rule := range rules {
+		conds := make([]string, 0)
+		for c, id := range eofRules {
+			if id == j {
+				conds = append(conds, fmt.Sprint(c))
+			}
+		}
+		if len(conds) == 0 {
+			continue
+		}
+		buf.WriteString(fmt.Sprintf("case %v:\n", strings.Join(conds, ", ")))
+		action, _ := genAction(lw, rule)
+		buf.WriteString(action)
+	}
+	buf.WriteString("}\n")
+
+	return buf.String(), false
+}
+
+// genAction returns Go code of the rule's action and whether the code ends with a terminating statement.
+func genAction(lw lineWriter, rule Rule) (action string, terminates bool) {
+	switch {
+	case rule.Skip:
+		return "", false
+	case rule.Token != "":
+		return lw.wrap(fmt.Sprintf("return %v, nil\n", rule.Token), rule.Pos), true
+	default:
+		return lw.wrap(rule.Action+"\n", rule.actionPos()), isTerminating(rule.Action)
+	}
+}
+
+// isTerminating reports whether the last statement of the action block is return, goto or panic.
+func isTerminating(action string) bool {
+	fset := token.NewFileSet()
+	f, err := parser.ParseFile(fset, "", "package p\nfunc _() "+action, parser.SkipObjectResolution)
+	if err != nil {
+		return false
+	}
+	body := f.Decls[0].(*ast.FuncDecl).Body.List
+	if len(body) == 0 {
+		return false
+	}
+
+	switch stmt := body[len(body)-1].(type) {
+	case *ast.ReturnStmt:
+		return true
+	case *ast.BranchStmt:
+		return stmt.Tok == token.GOTO
+	case *ast.ExprStmt:
+		call, ok := stmt.X.(*ast.CallExpr)
+		if !ok {
+			return false
+		}
+		id, ok := call.Fun.(*ast.Ident)
+		return ok && id.Name == "panic"
+	}
+
+	return false
+}
+
+func parse(regex string, caseless bool) *automata.NFA {
 	lex := regexp.NewLexer(regex)
 	tokens := lex.Scan()
 	parser := regexp.NewParser(tokens)
 	ast, _ := parser.Parse()
-	gen := regexp.NewCodeGenerator()
+	gen := regexp.NewCodeGenerator().SetCaseless(caseless)
 	ast.Accept(gen)
 
 	return gen.GetNFA()
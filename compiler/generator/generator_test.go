@@ -1,7 +1,13 @@
 package generator_test
 
 import (
+	"bufio"
+	"bytes"
 	"fmt"
+	"go/format"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/goropikari/tlex/automata"
@@ -185,6 +191,500 @@ func TestDFA_Accept(t *testing.T) {
 	}
 }
 
+func TestDFA_AcceptCaseless(t *testing.T) {
+	t.Parallel()
+
+	// [^a-z] precedes [a-z][a-z]* so that letters are matched by it if the negation ignores case.
+	regexs := []string{
+		"select|from",
+		"[^a-z]",
+		"[a-z][a-z]*",
+	}
+
+	tests := []struct {
+		name    string
+		given   string
+		accept  bool
+		regexID automata.RegexID
+	}{
+		{name: "lower keyword", given: "select", accept: true, regexID: 1},
+		{name: "upper keyword", given: "SELECT", accept: true, regexID: 1},
+		{name: "mixed keyword", given: "FrOm", accept: true, regexID: 1},
+		{name: "identifier", given: "Foo", accept: true, regexID: 3},
+		{name: "negated range", given: "Z", accept: true, regexID: 3},
+		{name: "negated range lower", given: "a", accept: true, regexID: 3},
+		{name: "negated range upper", given: "A", accept: true, regexID: 3},
+		{name: "kelvin sign", given: "\u212a", accept: true, regexID: 3},
+		{name: "digit", given: "1", accept: true, regexID: 2},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			dfa := generator.LexerCaselessNFA(regexs).ToImdNFA().ToDFA().LexerMinimize()
+
+			regexID, accept := dfa.Accept(tt.given)
+
+			require.Equal(t, tt.accept, accept)
+			require.Equal(t, tt.regexID, regexID)
+		})
+	}
+}
+
+func TestDFA_InitStates(t *testing.T) {
+	t.Parallel()
+
+	dfa := generator.LexerConditionNFA(
+		[]string{"a", "b", "c"},
+		[][]int{{0, 2}, {1, 2}, {0, 2}},
+	).ToImdNFA().ToDFA().LexerMinimize()
+
+	starts := dfa.GetInitStates()
+	require.Len(t, starts, 3)
+	require.NotEqual(t, starts[0], starts[1])
+	require.Equal(t, starts[0], starts[2]) // same rules make the same start state
+	require.Equal(t, dfa.GetInitState(), starts[0])
+}
+
+func TestGenerate_Token(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name  string
+		given string
+		err   error
+	}{
+		{
+			name: "declared tokens",
+			given: `
+%token Keyword Identifier
+%{
+const Other = 100
+%}
+%%
+if { return Keyword, nil }
+[a-z][a-z]* { x := Identifier; return x, nil }
+. { return Other, nil }
+%%
+`,
+		},
+		{
+			name: "undeclared token",
+			given: `
+%token Keyword
+%%
+if { return Keyword, nil }
+[a-z][a-z]* { return Identifier, nil }
+%%
+`,
+			err: generator.ErrUndeclaredToken,
+		},
+		{
+			name: "declarative rules",
+			given: `
+%%
+if -> Keyword
+[a-z][a-z]* -> Identifier
+. -> skip
+%%
+`,
+		},
+		{
+			name: "undeclared token in declarative rule",
+			given: `
+%token Keyword
+%%
+if -> Keyword
+[a-z][a-z]* -> Identifier
+%%
+`,
+			err: generator.ErrUndeclaredToken,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			r := bufio.NewReader(bytes.NewBufferString(tt.given))
+			err := generator.Generate(r, filepath.Join(t.TempDir(), "lex.go"))
+			if tt.err != nil {
+				require.ErrorIs(t, err, tt.err)
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestGenerate_LineDirective(t *testing.T) {
+	given := `%{
+import "strings"
+
+// upper converts s to upper case.
+var upper = strings.ToUpper
+%}
+%%
+a {
+	return 1, nil
+}
+b ` + `
+{
+	return 2, nil
+}
+c -> skip
+%%
+
+// main does nothing.
+func main() {}
+`
+	tests := []struct {
+		name     string
+		options  []generator.Option
+		expected []string
+	}{
+		{
+			name: "line directives",
+			expected: []string{
+				// directives are written before doc comments, where gofmt doesn't move them.
+				"//line lex.l:3\n\n// upper converts s to upper case.\nvar upper",
+				"//line lex.l:8\n\t\t\t{\n",
+				"//line lex.l:12\n\t\t\t{\n",
+				"//line lex.l:17\n\n// main does nothing.\nfunc main() {}\n",
+			},
+		},
+		{
+			name:    "noline",
+			options: []generator.Option{{Name: "noline"}},
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			dir := t.TempDir()
+			src := filepath.Join(dir, "lex.l")
+			out := filepath.Join(dir, "lex.go")
+			require.NoError(t, os.WriteFile(src, []byte(given), 0644))
+			require.NoError(t, generator.GenerateFile(src, out, tt.options...))
+
+			data, err := os.ReadFile(out)
+			require.NoError(t, err)
+			for _, s := range tt.expected {
+				require.Contains(t, string(data), s)
+			}
+			if len(tt.expected) == 0 {
+				require.NotContains(t, string(data), "//line")
+			}
+			formatted, err := format.Source(data)
+			require.NoError(t, err)
+			require.Equal(t, string(data), string(formatted))
+			// directives going back to the generated file point the following line.
+			for i, line := range strings.Split(string(data), "\n") {
+				if strings.HasPrefix(line, "//line lex.go:") {
+					require.Equal(t, fmt.Sprintf("//line lex.go:%v", i+2), line)
+				}
+			}
+		})
+	}
+}
+
+func TestFindShadowedRules(t *testing.T) {
+	tests := []struct {
+		name     string
+		given    string
+		expected []generator.ShadowedRule
+	}{
+		{
+			name: "no shadowed rule",
+			given: `%%
+[0-9][0-9]* { return 1, nil }
+[a-z][a-z]* { return 2, nil }
+%%
+`,
+			expected: []generator.ShadowedRule{},
+		},
+		{
+			name: "never matched",
+			given: `%%
+[a-z][a-z]* { return 1, nil }
+if|for { return 2, nil }
+%%
+`,
+			expected: []generator.ShadowedRule{
+				{
+					Index: 1,
+					Rule:  generator.Rule{Pos: generator.Pos{Line: 3}, Regex: "if|for", Action: "{ return 2, nil }"},
+					Never: true,
+					Witnesses: []generator.Witness{
+						{Text: "if", Condition: "INITIAL", WinnerIndex: 0, Winner: generator.Rule{Pos: generator.Pos{Line: 2}, Regex: "[a-z][a-z]*", Action: "{ return 1, nil }"}},
+						{Text: "for", Condition: "INITIAL", WinnerIndex: 0, Winner: generator.Rule{Pos: generator.Pos{Line: 2}, Regex: "[a-z][a-z]*", Action: "{ return 1, nil }"}},
+					},
+				},
+			},
+		},
+		{
+			name: "partially shadowed",
+			given: `%%
+ab -> skip
+[a-c][a-c]* -> skip
+%%
+`,
+			expected: []generator.ShadowedRule{
+				{
+					Index: 1,
+					Rule:  generator.Rule{Pos: generator.Pos{Line: 3}, Regex: "[a-c][a-c]*", Skip: true},
+					Witnesses: []generator.Witness{
+						{Text: "ab", Condition: "INITIAL", WinnerIndex: 0, Winner: generator.Rule{Pos: generator.Pos{Line: 2}, Regex: "ab", Skip: true}},
+					},
+				},
+			},
+		},
+		{
+			name: "shadowed in start condition",
+			given: `%x STR
+%%
+<STR>a -> skip
+<STR>a|b -> skip
+<*>c -> skip
+c -> skip
+%%
+`,
+			expected: []generator.ShadowedRule{
+				{
+					Index: 1,
+					Rule:  generator.Rule{Pos: generator.Pos{Line: 4}, Conditions: []string{"STR"}, Regex: "a|b", Skip: true},
+					Witnesses: []generator.Witness{
+						{Text: "a", Condition: "STR", WinnerIndex: 0, Winner: generator.Rule{Pos: generator.Pos{Line: 3}, Conditions: []string{"STR"}, Regex: "a", Skip: true}},
+					},
+				},
+				{
+					Index: 3,
+					Rule:  generator.Rule{Pos: generator.Pos{Line: 6}, Regex: "c", Skip: true},
+					Never: true,
+					Witnesses: []generator.Witness{
+						{Text: "c", Condition: "INITIAL", WinnerIndex: 2, Winner: generator.Rule{Pos: generator.Pos{Line: 5}, Conditions: []string{"*"}, Regex: "c", Skip: true}},
+					},
+				},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			r := bufio.NewReader(bytes.NewBufferString(tt.given))
+			spec, err := generator.NewParser(r).Parse()
+			require.NoError(t, err)
+			require.Equal(t, tt.expected, generator.FindShadowedRules(spec))
+		})
+	}
+}
+
+func TestFindOverlaps(t *testing.T) {
+	tests := []struct {
+		name     string
+		given    string
+		expected []generator.Overlap
+	}{
+		{
+			name: "disjoint rules",
+			given: `%%
+[0-9][0-9]* -> skip
+[a-z][a-z]* -> skip
+%%
+`,
+			expected: []generator.Overlap{},
+		},
+		{
+			name: "keyword and identifier",
+			given: `%%
+if|for -> skip
+[a-z][a-z]* -> skip
+[a-z][0-9]* -> skip
+%%
+`,
+			expected: []generator.Overlap{
+				{
+					First:  generator.OverlapRule{Index: 0, Pos: generator.Pos{Line: 2}, Regex: "if|for"},
+					Second: generator.OverlapRule{Index: 1, Pos: generator.Pos{Line: 3}, Regex: "[a-z][a-z]*"},
+					Text:   "if",
+					Winner: 0,
+				},
+				{
+					First:  generator.OverlapRule{Index: 1, Pos: generator.Pos{Line: 3}, Regex: "[a-z][a-z]*"},
+					Second: generator.OverlapRule{Index: 2, Pos: generator.Pos{Line: 4}, Regex: "[a-z][0-9]*"},
+					Text:   "a",
+					Winner: 1,
+				},
+			},
+		},
+		{
+			name: "third rule wins",
+			given: `%%
+a -> skip
+[a-b] -> skip
+[a-c] -> skip
+%%
+`,
+			expected: []generator.Overlap{
+				{
+					First:  generator.OverlapRule{Index: 0, Pos: generator.Pos{Line: 2}, Regex: "a"},
+					Second: generator.OverlapRule{Index: 1, Pos: generator.Pos{Line: 3}, Regex: "[a-b]"},
+					Text:   "a",
+					Winner: 0,
+				},
+				{
+					First:  generator.OverlapRule{Index: 0, Pos: generator.Pos{Line: 2}, Regex: "a"},
+					Second: generator.OverlapRule{Index: 2, Pos: generator.Pos{Line: 4}, Regex: "[a-c]"},
+					Text:   "a",
+					Winner: 0,
+				},
+				{
+					First:  generator.OverlapRule{Index: 1, Pos: generator.Pos{Line: 3}, Regex: "[a-b]"},
+					Second: generator.OverlapRule{Index: 2, Pos: generator.Pos{Line: 4}, Regex: "[a-c]"},
+					Text:   "a",
+					Winner: 0,
+				},
+			},
+		},
+		{
+			name: "different start conditions",
+			given: `%x STR
+%%
+a -> skip
+<STR>a -> skip
+%%
+`,
+			expected: []generator.Overlap{
+				{
+					First:  generator.OverlapRule{Index: 0, Pos: generator.Pos{Line: 3}, Regex: "a"},
+					Second: generator.OverlapRule{Index: 1, Pos: generator.Pos{Line: 4}, Regex: "a"},
+					Text:   "a",
+					Winner: -1,
+				},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			r := bufio.NewReader(bytes.NewBufferString(tt.given))
+			spec, err := generator.NewParser(r).Parse()
+			require.NoError(t, err)
+			require.Equal(t, tt.expected, generator.FindOverlaps(spec))
+		})
+	}
+}
+
+func TestVet(t *testing.T) {
+	tests := []struct {
+		name     string
+		given    string
+		expected []generator.Diagnostic
+	}{
+		{
+			name: "no finding",
+			given: `%token Ident
+%%
+[a-z][a-z]* -> Ident
+.|\n -> skip
+%%
+`,
+			expected: []generator.Diagnostic{},
+		},
+		{
+			name: "findings",
+			given: `%token Ident Number Unused
+%%
+[a-z]* -> Ident
+[0-9][0-9]* {
+	if len(yylex.YYText) > 1 {
+		return Number, nil
+	}
+}
+if -> Ident
+%%
+`,
+			expected: []generator.Diagnostic{
+				{Pos: generator.Pos{Line: 1}, Severity: generator.SeverityWarning, Message: "token Unused is never used"},
+				{Pos: generator.Pos{Line: 2}, Severity: generator.SeverityWarning, Message: "no rule matches \"\\x00\" in INITIAL, so Next returns ErrYYScan; add a catch-all rule such as `.`"},
+				{Pos: generator.Pos{Line: 3}, Severity: generator.SeverityWarning, Message: "rule [a-z]* matches the empty string, which is never accepted"},
+				{Pos: generator.Pos{Line: 4}, Severity: generator.SeverityWarning, Message: "action of rule [0-9][0-9]* may not return; scanning continues when it doesn't"},
+				{Pos: generator.Pos{Line: 9}, Severity: generator.SeverityError, Message: "rule if can never be matched"},
+			},
+		},
+		{
+			name: "condition without catch-all",
+			given: `%token Ident
+%x DONE
+%%
+[a-z][a-z]* -> Ident
+.|\n -> skip
+<DONE>[a-z] -> Ident
+%%
+`,
+			expected: []generator.Diagnostic{
+				{Pos: generator.Pos{Line: 2}, Severity: generator.SeverityWarning, Message: "no rule matches \"\\x00\" in DONE, so Next returns ErrYYScan; add a catch-all rule such as `.`"},
+			},
+		},
+		{
+			name: "echo without catch-all",
+			given: `%option recover=echo
+%token Ident
+%%
+[a-z][a-z]* -> Ident
+%%
+`,
+			expected: []generator.Diagnostic{},
+		},
+		{
+			name: "collected errors without catch-all",
+			given: `%option collecterrors
+%token Ident
+%%
+[a-z][a-z]* -> Ident
+%%
+`,
+			expected: []generator.Diagnostic{},
+		},
+		{
+			name: "undeclared token",
+			given: `%token Ident
+%%
+[a-z][a-z]* -> Ident
+.|\n { return Other, nil }
+%%
+`,
+			expected: []generator.Diagnostic{
+				{Pos: generator.Pos{Line: 4}, Severity: generator.SeverityError, Message: "undeclared token: Other"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			r := bufio.NewReader(bytes.NewBufferString(tt.given))
+			spec, err := generator.NewParser(r).Parse()
+			require.NoError(t, err)
+			require.Equal(t, tt.expected, generator.Vet(spec))
+		})
+	}
+}
+
 func TestDot(t *testing.T) {
 	// _, _ = generator.LexerNFA([]string{"a", "abb", "a*bb*"}).
 	// 	ToImdNFA().
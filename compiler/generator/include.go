@@ -0,0 +1,125 @@
+package generator
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+const includeDirective = "%include"
+
+// includer expands `%include "file"` lines of definitions and rules sections.
+// It remembers the original position of every expanded line.
+type includer struct {
+	stack   []string // absolute paths of files being expanded
+	lines   []string
+	origins []Pos
+}
+
+func newIncluder() *includer {
+	return &includer{
+		stack:   make([]string, 0),
+		lines:   make([]string, 0),
+		origins: make([]Pos, 0),
+	}
+}
+
+func (inc *includer) String() string {
+	return strings.Join(inc.lines, "")
+}
+
+// expandFile expands the top level lexer configuration file.
+// `%include` in user code section and embedded code is kept as it is.
+func (inc *includer) expandFile(filename string, src string) error {
+	if filename != "" {
+		abs, err := filepath.Abs(filename)
+		if err != nil {
+			return err
+		}
+		inc.stack = append(inc.stack, abs)
+	}
+
+	section := 0
+	inCode := false
+	for i, line := range strings.SplitAfter(src, "\n") {
+		pos := Pos{File: filename, Line: i + 1}
+		switch {
+		case line == "%{\n":
+			inCode = true
+		case line == "%}\n":
+			inCode = false
+		case line == "%%\n" && !inCode:
+			section++
+		case section < 2 && !inCode && strings.HasPrefix(line, includeDirective):
+			if err := inc.include(pos, line); err != nil {
+				return err
+			}
+			continue
+		}
+		inc.lines = append(inc.lines, line)
+		inc.origins = append(inc.origins, pos)
+	}
+
+	return nil
+}
+
+// include expands `%include "file"` line. The file is resolved relative to the including file.
+func (inc *includer) include(pos Pos, line string) error {
+	arg := strings.TrimSpace(strings.TrimPrefix(line, includeDirective))
+	name, err := strconv.Unquote(arg)
+	if err != nil || name == "" {
+		return newSpecError(pos, fmt.Errorf("%w: %v", ErrInvalidInclude, strings.TrimSpace(line)))
+	}
+	if !filepath.IsAbs(name) {
+		name = filepath.Join(filepath.Dir(pos.File), name)
+	}
+	abs, err := filepath.Abs(name)
+	if err != nil {
+		return newSpecError(pos, err)
+	}
+	for i, p := range inc.stack {
+		if p == abs {
+			chain := append(inc.stack[i:len(inc.stack):len(inc.stack)], abs)
+			return newSpecError(pos, fmt.Errorf("%w: %v", ErrIncludeCycle, strings.Join(chain, " -> ")))
+		}
+	}
+	data, err := os.ReadFile(name)
+	if err != nil {
+		return newSpecError(pos, err)
+	}
+
+	inc.stack = append(inc.stack, abs)
+	defer func() {
+		inc.stack = inc.stack[:len(inc.stack)-1]
+	}()
+
+	inCode := false
+	for i, line := range strings.SplitAfter(string(data), "\n") {
+		if line == "" {
+			continue
+		}
+		if !strings.HasSuffix(line, "\n") {
+			line += "\n"
+		}
+		pos := Pos{File: name, Line: i + 1}
+		switch {
+		case line == "%{\n":
+			inCode = true
+		case line == "%}\n":
+			inCode = false
+		case line == "%%\n" && !inCode:
+			return newSpecError(pos, fmt.Errorf("%w: %%%% in included file", ErrInvalidInclude))
+		case !inCode && strings.HasPrefix(line, includeDirective):
+			if err := inc.include(pos, line); err != nil {
+				return err
+			}
+			continue
+		}
+		inc.lines = append(inc.lines, line)
+		inc.origins = append(inc.origins, pos)
+	}
+
+	return nil
+}
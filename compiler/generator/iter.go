@@ -0,0 +1,31 @@
+package generator
+
+import "strings"
+
+// iterTmpl is the file of the range-over-func iterator of the generated lexer.
+// It is a separate file because the iter package needs Go 1.23.
+const iterTmpl = `// Code generated by tlex. DO NOT EDIT.
+
+//go:build go1.23
+
+package {{ .PackageName }}
+
+import "iter"
+
+// All returns an iterator over the rest of the tokens.
+// An error other than io.EOF is yielded and ends the iteration.
+// Breaking the loop leaves the lexer just after the last yielded token.
+//
+//	for tok, err := range lex.All() {
+//		...
+//	}
+func (yylex *{{ .LexerName }}) All() iter.Seq2[{{ .PublicPrefix }}Token, error] {
+	return yylex.Each
+}
+`
+
+// iterFilename returns the name of the iterator file of the lexer file outfile,
+// e.g. lexer_iter.go for lexer.go.
+func iterFilename(outfile string) string {
+	return strings.TrimSuffix(outfile, ".go") + "_iter.go"
+}
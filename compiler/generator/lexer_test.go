@@ -0,0 +1,448 @@
+package generator_test
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/goropikari/tlex/compiler/generator"
+	"github.com/stretchr/testify/require"
+)
+
+// generateLexer generates the lexer of spec to out and returns the generated source.
+func generateLexer(t *testing.T, out, spec string, opts ...generator.Option) string {
+	t.Helper()
+
+	require.NoError(t, generator.Generate(bufio.NewReader(strings.NewReader(spec)), out, opts...))
+	data, err := os.ReadFile(out)
+	require.NoError(t, err)
+
+	return string(data)
+}
+
+// lexerFile is a lexer generated to the file of a test program.
+type lexerFile struct {
+	name string
+	spec string
+	opts []generator.Option
+}
+
+// runLexers generates the lexers in package main, builds them with main, which is the source of main.go,
+// and returns the standard output of the program run with args.
+func runLexers(t *testing.T, lexers []lexerFile, main string, args []string) string {
+	t.Helper()
+	if testing.Short() {
+		t.Skip("building a generated lexer is skipped in short mode")
+	}
+
+	dir := t.TempDir()
+	for _, l := range lexers {
+		generateLexer(t, filepath.Join(dir, l.name), l.spec, append(l.opts, generator.Option{Name: "package", Value: "main"})...)
+	}
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "go.mod"), []byte("module lexer\n\ngo 1.19\n"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "main.go"), []byte(main), 0644))
+
+	var stdout, stderr bytes.Buffer
+	cmd := exec.Command("go", append([]string{"run", "."}, args...)...)
+	cmd.Dir = dir
+	cmd.Env = append(os.Environ(), "GOWORK=off", "GOFLAGS=-mod=mod")
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	require.NoError(t, cmd.Run(), stderr.String())
+
+	return stdout.String()
+}
+
+// runLexer is runLexers with one lexer generated to lexer.go.
+func runLexer(t *testing.T, spec, main string, args []string, opts ...generator.Option) string {
+	t.Helper()
+
+	return runLexers(t, []lexerFile{{name: "lexer.go", spec: spec, opts: opts}}, main, args)
+}
+
+// printTokens returns a main function printing the tokens of the input given by os.Args
+// with the lexer of the public prefix.
+func printTokens(publicPrefix string) string {
+	return fmt.Sprintf(printTokensMain, publicPrefix)
+}
+
+const printTokensMain = `package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+func main() {
+	lex := New%[1]s(strings.NewReader(os.Args[1]))
+	for {
+		kind, err := lex.Next()
+		if errors.Is(err, io.EOF) {
+			return
+		} else if err != nil {
+			fmt.Println("error:", err)
+			continue
+		}
+		fmt.Printf("%%v %%q %%v-%%v\n", kind, lex.YYText, lex.Pos(), lex.EndPos())
+	}
+}
+`
+
+// lexTokens is the spec of the tests of the generated lexer.
+// It has keywords shadowing identifiers, backtracking, runes out of Latin-1 and start conditions.
+const lexTokens = `%token Keyword Ident Number Float Arrow Kana Str
+%x STR
+%%
+if|for -> Keyword
+[a-z_][a-z0-9_]* -> Ident
+[0-9][0-9]* -> Number
+[0-9][0-9]*[.][0-9][0-9]* -> Float
+"->" -> Arrow
+[ぁ-ゔ][ぁ-ゔ]* -> Kana
+' { yylex.Begin(STR) }
+<STR>[^'][^']* -> Str
+<STR>' { yylex.Begin(INITIAL) }
+[ \n] -> skip
+%%
+`
+
+// lexTokensInput is lexed by lexTokens. "1." backtracks to "1" since no digit follows ".".
+const lexTokensInput = "if iffy 12.5 1.x ->あいう\n'for ア' _a1"
+
+const lexTokensOutput = `Keyword "if" 1:1-1:3
+Ident "iffy" 1:4-1:8
+Float "12.5" 1:9-1:13
+Number "1" 1:14-1:15
+error: 1:15: failed to scan "."
+Ident "x" 1:16-1:17
+Arrow "->" 1:18-1:20
+Kana "あいう" 1:20-1:23
+Str "for ア" 2:2-2:7
+Ident "_a1" 2:9-2:12
+`
+
+func TestGenerate_Backend(t *testing.T) {
+	for _, backend := range []string{generator.BackendTable, generator.BackendGoto} {
+		backend := backend
+		t.Run(backend, func(t *testing.T) {
+			t.Parallel()
+
+			out := runLexer(t, lexTokens, printTokens(""), []string{lexTokensInput}, generator.Option{Name: "backend", Value: backend})
+			require.Equal(t, lexTokensOutput, out)
+		})
+	}
+}
+
+func TestGenerate_EmptyCondition(t *testing.T) {
+	// DONE has no rules but <<EOF>>, which ends lexing after End.
+	given := `%token Word End
+%x DONE
+%%
+[a-z][a-z]* -> Word
+<<EOF>> {
+	yylex.Begin(DONE)
+	return End, nil
+}
+<DONE><<EOF>> { }
+%%
+`
+	for _, backend := range []string{generator.BackendTable, generator.BackendGoto} {
+		backend := backend
+		t.Run(backend, func(t *testing.T) {
+			t.Parallel()
+
+			out := runLexer(t, given, printTokens(""), []string{"ab"}, generator.Option{Name: "backend", Value: backend})
+			require.Equal(t, "Word \"ab\" 1:1-1:3\nEnd \"\" 1:3-1:3\n", out)
+		})
+	}
+}
+
+func TestGenerate_TableFormat(t *testing.T) {
+	for _, format := range []string{generator.TableFormatMap, generator.TableFormatSorted, generator.TableFormatClass} {
+		format := format
+		t.Run(format, func(t *testing.T) {
+			t.Parallel()
+
+			out := runLexer(t, lexTokens, printTokens(""), []string{lexTokensInput}, generator.Option{Name: "table", Value: format})
+			require.Equal(t, lexTokensOutput, out)
+		})
+	}
+}
+
+// lexWords lexes the words out of parentheses as tokens of kind %[2]s.
+// %[1]s is the public prefix of the lexer.
+const lexWords = `%%token %[2]s
+%%x PAREN
+%%%%
+[a-z][a-z]* -> %[2]s
+"(" { yylex.Begin(%[1]sPAREN) }
+<PAREN>")" { yylex.Begin(%[1]sINITIAL) }
+<PAREN>[^)] -> skip
+[^a-z(] -> skip
+%%%%
+`
+
+func TestGenerate_Prefix(t *testing.T) {
+	// every declaration of the options must be prefixed for both lexers to build in one package.
+	opts := []generator.Option{{Name: "globaltext"}, {Name: "input", Value: "runereadseeker"}, {Name: "displaycolumn"}, {Name: "yylineno"}}
+	lexers := []lexerFile{
+		{name: "yy.go", spec: fmt.Sprintf(lexWords, "", "Ident"), opts: opts},
+		{name: "word.go", spec: fmt.Sprintf(lexWords, "Word", "Word"), opts: append([]generator.Option{{Name: "prefix", Value: "word"}}, opts...)},
+	}
+	main := `package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+)
+
+func main() {
+	yy := New(strings.NewReader(os.Args[1]))
+	kind, err := yy.Next()
+	fmt.Println(kind, YYText, yy.YYLineno, err)
+	yyToks, err := yy.Tokens()
+	fmt.Println(yyToks, err)
+
+	word := NewWord(strings.NewReader(os.Args[1]))
+	wordKind, err := word.Next()
+	fmt.Println(wordKind, WordText, word.YYLineno, err)
+	wordToks, err := word.Tokens()
+	fmt.Println(wordToks, err)
+}
+`
+
+	out := runLexers(t, lexers, main, []string{"ab (cd\n) ef"})
+	require.Equal(t, `Ident ab 1 <nil>
+[{Ident ef 2:3 2:5}] <nil>
+Word ab 1 <nil>
+[{Word ef 2:3 2:5}] <nil>
+`, out)
+}
+
+const printAllMain = `//go:build go1.23
+
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+)
+
+func main() {
+	for tok, err := range New(strings.NewReader(os.Args[1])).All() {
+		if err != nil {
+			fmt.Println("error:", err)
+			continue
+		}
+		fmt.Printf("%v %q %v-%v\n", tok.Kind, tok.Text, tok.Start, tok.End)
+	}
+}
+`
+
+func TestGenerate_Iter(t *testing.T) {
+	t.Run("iter", func(t *testing.T) {
+		t.Parallel()
+
+		out := runLexer(t, lexTokens, printAllMain, []string{"if x"}, generator.Option{Name: "iter"})
+		require.Equal(t, "Keyword \"if\" 1:1-1:3\nIdent \"x\" 1:4-1:5\n", out)
+	})
+
+	t.Run("default", func(t *testing.T) {
+		t.Parallel()
+
+		dir := t.TempDir()
+		generateLexer(t, filepath.Join(dir, "lexer.go"), lexTokens)
+		_, err := os.Stat(filepath.Join(dir, "lexer_iter.go"))
+		require.ErrorIs(t, err, os.ErrNotExist)
+	})
+}
+
+func TestGenerate_NextToken(t *testing.T) {
+	main := `package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+)
+
+func main() {
+	lex := New(strings.NewReader(os.Args[1]))
+	tok, err := lex.NextToken()
+	fmt.Printf("%v %q %v-%v %v\n", tok.Kind, tok.Text, tok.Start, tok.End, err)
+	toks, err := lex.Tokens()
+	fmt.Println(toks, err)
+	toks, err = lex.Tokens()
+	fmt.Println(toks, err)
+	tok, err = lex.NextToken()
+	fmt.Println(tok.Start, tok.End, err)
+}
+`
+
+	out := runLexer(t, lexTokens, main, []string{"if 1.x\n->"})
+	require.Equal(t, `Keyword "if" 1:1-1:3 <nil>
+[{Number 1 1:4 1:5}] 1:5: failed to scan "."
+[{Ident x 1:6 1:7} {Arrow -> 2:1 2:3}] <nil>
+2:3 2:3 EOF
+`, out)
+}
+
+func TestGenerate_ScanError(t *testing.T) {
+	given := `%token Float
+%x PAREN
+%%
+[0-9][0-9]*[.][0-9][0-9]* -> Float
+"(" { yylex.Begin(PAREN) }
+<PAREN>")" { yylex.Begin(INITIAL) }
+<PAREN>[0-9] -> skip
+%%
+`
+	main := `package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+func main() {
+	lex := New(strings.NewReader(os.Args[1]))
+	for {
+		_, err := lex.Next()
+		if errors.Is(err, io.EOF) {
+			return
+		}
+		var serr *ScanError
+		if errors.As(err, &serr) {
+			fmt.Printf("%v %q %q %d %v %v\n", serr.Pos, serr.Text, serr.Partial, serr.Rune, yyConditionNames[serr.Condition], errors.Is(err, ErrYYScan))
+		}
+		fmt.Println(err)
+	}
+}
+`
+
+	out := runLexer(t, given, main, []string{"1.x(1a)2."})
+	// "1." gets stuck at 'x' (120) and "2." at the end of the input (-1), and "." (46) and "a" (97) start no rule.
+	require.Equal(t, `1:1 "1" "1." 120 INITIAL true
+1:1: failed to scan "1"
+1:2 "." "" 46 INITIAL true
+1:2: failed to scan "."
+1:3 "x" "" 120 INITIAL true
+1:3: failed to scan "x"
+1:6 "a" "" 97 PAREN true
+1:6: failed to scan "a" in start condition PAREN
+1:8 "2" "2." -1 INITIAL true
+1:8: failed to scan "2"
+1:9 "." "" 46 INITIAL true
+1:9: failed to scan "."
+`, out)
+}
+
+func TestGenerate_Less(t *testing.T) {
+	given := `%token Word
+%%
+[a-z][a-z]* {
+	fmt.Println(yylex.Less(-1), yylex.Less(len(yylex.Bytes())+1), yylex.Less(1))
+	return Word, nil
+}
+%%
+`
+
+	out := runLexer(t, given, printTokens(""), []string{"ab"})
+	require.Equal(t, `negative count count exceeds the current token <nil>
+Word "a" 1:1-1:2
+negative count count exceeds the current token <nil>
+Word "b" 1:2-1:3
+`, out)
+}
+
+func TestGenerate_ReadInput(t *testing.T) {
+	given := "%token Code Comment Field\n%%\n" +
+		"`" + ` {
+	if _, err := yylex.ReadUntil("` + "`" + `"); err != nil {
+		return 0, err
+	}
+	return Code, nil
+}
+"/*" {
+	for {
+		r, _, err := yylex.ReadRune()
+		if err != nil {
+			return 0, err
+		}
+		if p, _ := yylex.PeekRune(); r == '*' && p == '/' {
+			yylex.ReadRune()
+			return Comment, nil
+		}
+	}
+}
+[0-9]: {
+	if _, err := yylex.Consume(int(yylex.YYText[0] - '0')); err != nil {
+		return 0, err
+	}
+	return Field, nil
+}
+"-1:" {
+	if _, err := yylex.Consume(-1); err != nil {
+		return 0, err
+	}
+	return Field, nil
+}
+[ \n] -> skip
+%%
+`
+
+	tests := []struct {
+		name     string
+		given    string
+		expected string
+	}{
+		{
+			name:     "read",
+			given:    "`a b` /* x *\n*/ 4:aあ 0:",
+			expected: "Code \"`a b`\" 1:1-1:6\nComment \"/* x *\\n*/\" 1:7-2:3\nField \"4:aあ\" 2:4-2:8\nField \"0:\" 2:9-2:11\n",
+		},
+		{
+			name:     "unterminated code",
+			given:    "`a b",
+			expected: "error: unexpected EOF\n",
+		},
+		{
+			name:     "unterminated comment",
+			given:    "/* x",
+			expected: "error: unexpected EOF\n",
+		},
+		{
+			name:     "short field",
+			given:    "3:ab",
+			expected: "error: unexpected EOF\n",
+		},
+		{
+			name:     "negative field",
+			given:    "-1: 0:",
+			expected: "error: negative count\nField \"0:\" 1:5-1:7\n",
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			out := runLexer(t, given, printTokens(""), []string{tt.given})
+			require.Equal(t, tt.expected, out)
+		})
+	}
+}
@@ -0,0 +1,267 @@
+package generator
+
+import (
+	"bytes"
+	"fmt"
+	"go/scanner"
+	"go/token"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// Directives are written as placeholder comments while the generated code is formatted
+// because the formatter interprets `//line` directives and moves directive-like comments.
+// generatedLine switches positions back to the generated file and
+// resolveLineDirectives replaces it with the actual file name and line.
+const (
+	linePlaceholder = "// tlex-line "
+	generatedLine   = linePlaceholder + "<generated>"
+)
+
+// lineWriter writes `//line` directives which map code copied from the lexer configuration
+// back to its original position. It writes nothing if the position of the code is unknown.
+type lineWriter struct {
+	enabled bool
+	dir     string // directory of the generated file
+}
+
+func newLineWriter(cfg Config, outfile string) lineWriter {
+	return lineWriter{
+		enabled: cfg.Line,
+		dir:     filepath.Dir(outfile),
+	}
+}
+
+// directive returns `//line file:N` line. Relative file names in line directives
+// are resolved relative to the generated file, so pos.File is converted so.
+func (lw lineWriter) directive(pos Pos) string {
+	if !lw.enabled || pos.File == "" || pos.Line <= 0 {
+		return ""
+	}
+	file := pos.File
+	if abs, err := filepath.Abs(file); err == nil {
+		if dir, err := filepath.Abs(lw.dir); err == nil {
+			if rel, err := filepath.Rel(dir, abs); err == nil {
+				file = rel
+			}
+		}
+	}
+
+	return fmt.Sprintf("%v%v:%v\n", linePlaceholder, filepath.ToSlash(file), pos.Line)
+}
+
+// wrap surrounds the code beginning at pos with directives.
+func (lw lineWriter) wrap(code string, pos Pos) string {
+	origins := make([]Pos, strings.Count(code, "\n")+1)
+	for i := range origins {
+		origins[i] = Pos{File: pos.File, Line: pos.Line + i}
+	}
+
+	return lw.wrapLines(code, origins)
+}
+
+// wrapLines writes the code with directives. origins holds the position of each line of the code.
+// Import declarations are moved to the beginning without directives because goimports merges them.
+// gofmt squeezes blank lines, so a directive is written after them as well as
+// wherever lines are not consecutive, e.g. blocks of different files.
+// Directives are written before lines beginning with code, not in comments or raw strings.
+// The code ends with the directive going back to the generated file.
+func (lw lineWriter) wrapLines(code string, origins []Pos) string {
+	if !lw.enabled || code == "" {
+		return code
+	}
+	imports, codeLines := scanLines(code)
+
+	var decls, buf bytes.Buffer
+	var prev Pos
+	wrapped, pending := false, false
+	for i, line := range strings.SplitAfter(strings.TrimSuffix(code, "\n"), "\n") {
+		var pos Pos
+		if i < len(origins) {
+			pos = origins[i]
+		}
+		switch {
+		case imports[i+1]:
+			decls.WriteString(strings.TrimSuffix(line, "\n") + "\n")
+			prev = Pos{}
+			continue
+		case pos.File != prev.File || pos.Line != prev.Line+1 || strings.TrimSpace(line) == "":
+			pending = true
+		}
+		if pending && codeLines[i+1] {
+			if d := lw.directive(pos); d != "" {
+				buf.WriteString(d)
+				wrapped = true
+			}
+			pending = false
+		}
+		buf.WriteString(strings.TrimSuffix(line, "\n") + "\n")
+		prev = pos
+	}
+	if wrapped {
+		buf.WriteString(generatedLine + "\n")
+	}
+
+	return decls.String() + buf.String()
+}
+
+// scanLines returns lines (1-origin) of import declarations and lines beginning with a token
+// other than comments.
+func scanLines(src string) (imports, code map[int]bool) {
+	imports = make(map[int]bool)
+	code = make(map[int]bool)
+	seen := make(map[int]bool)
+
+	fset := token.NewFileSet()
+	file := fset.AddFile("", fset.Base(), len(src))
+	var s scanner.Scanner
+	s.Init(file, []byte(src), nil, scanner.ScanComments)
+
+	importLine, depth := 0, 0
+	for {
+		pos, tok, lit := s.Scan()
+		if tok == token.EOF {
+			break
+		}
+		begin := file.Line(pos)
+		if !seen[begin] && (tok != token.SEMICOLON || lit == ";") {
+			seen[begin] = true
+			code[begin] = tok != token.COMMENT
+		}
+
+		switch {
+		case tok == token.IMPORT && importLine == 0:
+			importLine, depth = begin, 0
+		case importLine == 0:
+		case tok == token.LPAREN:
+			depth++
+		case tok == token.RPAREN:
+			depth--
+		case tok == token.SEMICOLON && depth == 0:
+			for l := importLine; l <= begin; l++ {
+				imports[l] = true
+			}
+			importLine = 0
+		}
+	}
+
+	return imports, code
+}
+
+// resolveLineDirectives replaces placeholders with `//line` directives.
+// Directives must begin at the first column of a line.
+func resolveLineDirectives(src []byte, outfile string) []byte {
+	lines := strings.SplitAfter(string(src), "\n")
+	for i, line := range lines {
+		line = strings.TrimSpace(line)
+		if strings.HasPrefix(line, linePlaceholder) {
+			lines[i] = "//line " + strings.TrimPrefix(line, linePlaceholder) + "\n"
+		}
+	}
+	lines = separateDirectives(lines)
+
+	// lines are fixed, so directives going back to the generated file can point the following line.
+	var buf bytes.Buffer
+	for i, line := range lines {
+		if line == "//line "+strings.TrimPrefix(generatedLine, linePlaceholder)+"\n" {
+			line = fmt.Sprintf("//line %v:%v\n", filepath.Base(outfile), i+2)
+		}
+		buf.WriteString(line)
+	}
+
+	return buf.Bytes()
+}
+
+// separateDirectives keeps directives out of doc comments, where gofmt moves them to the end
+// after an empty `//` line. A directive just after a comment group is moved before the group
+// with a blank line, and a blank line is inserted between a directive and a comment group after it.
+// The lines of the directives are adjusted to the moved lines.
+func separateDirectives(lines []string) []string {
+	ret := make([]string, 0, len(lines))
+	for i := 0; i < len(lines); i++ {
+		d, ok := parseDirective(lines[i])
+		if !ok {
+			ret = append(ret, lines[i])
+			continue
+		}
+		// comment lines just before the directive, which belong to the following declaration.
+		c := 0
+		for c < len(ret) && isDocLine(ret[len(ret)-1-c]) {
+			c++
+		}
+		switch {
+		case c == 0 && i+1 < len(lines) && isDocLine(lines[i+1]) && d.shift(-1):
+			ret = append(ret, d.String(), "\n")
+		case c == 0:
+			ret = append(ret, lines[i])
+		case d.shift(-c - 1):
+			group := append([]string{}, ret[len(ret)-c:]...)
+			ret = append(ret[:len(ret)-c], d.String(), "\n")
+			ret = append(ret, group...)
+		default:
+			// the group can't be moved before the beginning of the file, so it is detached.
+			ret = append(ret, "\n", lines[i])
+		}
+	}
+
+	return ret
+}
+
+// directive is a `//line file:N` line. line is 0 for the placeholder going back to the generated file,
+// whose line is resolved after the lines are fixed.
+type directive struct {
+	file string
+	line int
+}
+
+// parseDirective parses a `//line file:N` line or the placeholder going back to the generated file.
+func parseDirective(s string) (*directive, bool) {
+	s = strings.TrimSuffix(s, "\n")
+	if !strings.HasPrefix(s, "//line ") {
+		return nil, false
+	}
+	s = strings.TrimPrefix(s, "//line ")
+	i := strings.LastIndex(s, ":")
+	if i < 0 {
+		return &directive{file: s}, true
+	}
+	n, err := strconv.Atoi(s[i+1:])
+	if err != nil {
+		return &directive{file: s}, true
+	}
+
+	return &directive{file: s[:i], line: n}, true
+}
+
+// shift moves the directive by n lines. It fails if the line gets before the beginning of the file.
+func (d *directive) shift(n int) bool {
+	if d.line == 0 {
+		return true
+	}
+	if d.line+n < 1 {
+		return false
+	}
+	d.line += n
+
+	return true
+}
+
+func (d *directive) String() string {
+	if d.line == 0 {
+		return "//line " + d.file + "\n"
+	}
+
+	return fmt.Sprintf("//line %v:%v\n", d.file, d.line)
+}
+
+// isDocLine reports whether the line is a line comment at the first column other than directives,
+// which can be a part of a doc comment.
+func isDocLine(line string) bool {
+	if !strings.HasPrefix(line, "//") || strings.HasPrefix(line, "//go:") {
+		return false
+	}
+	_, ok := parseDirective(line)
+
+	return !ok
+}
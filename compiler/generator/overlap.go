@@ -0,0 +1,101 @@
+package generator
+
+import (
+	"fmt"
+
+	"github.com/goropikari/tlex/automata"
+)
+
+// Overlap is a pair of rules whose languages intersect.
+type Overlap struct {
+	First  OverlapRule `json:"first"`
+	Second OverlapRule `json:"second"`
+	Text   string      `json:"text"`   // one of the shortest strings matched by both rules
+	Winner int         `json:"winner"` // index of the rule winning Text, or -1 if the rules are not active in the same start condition
+}
+
+// OverlapRule is a rule in Overlap.
+type OverlapRule struct {
+	Index int    `json:"index"` // index of the rule in Spec.Rules
+	Pos   Pos    `json:"pos"`
+	Regex string `json:"regex"`
+}
+
+func (o Overlap) String() string {
+	s := fmt.Sprintf("%v: rules %v and %v (%v) overlap: %q", o.First.Pos, o.First.Regex, o.Second.Regex, o.Second.Pos, o.Text)
+	switch o.Winner {
+	case -1:
+		return s + " (never compete)"
+	case o.First.Index:
+		return s + fmt.Sprintf(" is matched by %v", o.First.Regex)
+	case o.Second.Index:
+		return s + fmt.Sprintf(" is matched by %v", o.Second.Regex)
+	}
+
+	return s + fmt.Sprintf(" is matched by rule %v", o.Winner+1)
+}
+
+// MarshalText makes Pos a string in JSON.
+func (pos Pos) MarshalText() ([]byte, error) {
+	return []byte(pos.String()), nil
+}
+
+// FindOverlaps reports every pair of rules matching a common string.
+// The product of automata of each pair is searched for the shortest common string.
+// The winner is decided in the first start condition where both rules are active.
+func FindOverlaps(spec *Spec) []Overlap {
+	dfas := make([]*automata.DFA, 0, len(spec.Rules))
+	for _, rule := range spec.Rules {
+		dfas = append(dfas, parse(rule.Regex, spec.Config.Caseless).ToImdNFA().ToDFA())
+	}
+	active := spec.activeRules()
+
+	overlaps := make([]Overlap, 0)
+	for i := range spec.Rules {
+		for j := i + 1; j < len(spec.Rules); j++ {
+			text, ok := dfas[i].ShortestCommonString(dfas[j])
+			if !ok {
+				continue
+			}
+			overlaps = append(overlaps, Overlap{
+				First:  overlapRule(spec, i),
+				Second: overlapRule(spec, j),
+				Text:   text,
+				Winner: winner(dfas, active, i, j, text),
+			})
+		}
+	}
+
+	return overlaps
+}
+
+func overlapRule(spec *Spec, i int) OverlapRule {
+	return OverlapRule{Index: i, Pos: spec.Rules[i].Pos, Regex: spec.Rules[i].Regex}
+}
+
+// winner returns the earliest rule matching text in the first start condition where i-th and j-th rules are active.
+func winner(dfas []*automata.DFA, active [][]int, i, j int, text string) int {
+	for _, ids := range active {
+		if !containsInt(ids, i) || !containsInt(ids, j) {
+			continue
+		}
+		// ids are in ascending order.
+		for _, id := range ids {
+			if _, ok := dfas[id].Accept(text); ok {
+				return id
+			}
+		}
+	}
+
+	return -1
+}
+
+func containsInt(xs []int, x int) bool {
+	for _, v := range xs {
+		if v == x {
+			return true
+		}
+	}
+
+	return false
+}
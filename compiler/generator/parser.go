@@ -4,21 +4,97 @@ import (
 	"bufio"
 	"bytes"
 	"errors"
+	"fmt"
+	"go/token"
 	"io"
+	"strings"
 )
 
+// %option name=value
+// %token Name1 Name2
+// %s INCLUSIVE_CONDITION
+// %x EXCLUSIVE_CONDITION
+// %include "file.l"
 // %{
 // // Definitions
 // %}
 //
 // %%
-// Rules
+// pattern { action }
+// pattern -> Token
+// pattern -> skip
+// <COND1,COND2>pattern { action }
+// <<EOF>> { action }
+// <COND><<EOF>> { action }
+// %include "rules.l"
 // %%
 //
 // User code section
 
+// Spec is a parsed lexer configuration file.
+type Spec struct {
+	Config     Config
+	Tokens     []string
+	Conditions []Condition // declared start conditions except INITIAL
+	Definition string
+	Rules      []Rule
+	EOFRules   []Rule
+	UserCode   string
+
+	filename          string
+	tokenPos          []Pos // position where each token is declared
+	conditionPos      []Pos // position where each start condition is declared
+	rulesPos          Pos   // position of the `%%` line which begins the rules section
+	definitionOrigins []Pos // original position of each line of Definition
+	userCodePos       Pos
+}
+
+const (
+	skipRule = "skip"
+	eofRule  = "<<EOF>>"
+)
+
+// Pos is a position in the lexer configuration file.
+type Pos struct {
+	File string
+	Line int
+}
+
+func (pos Pos) String() string {
+	if pos.File == "" {
+		return fmt.Sprintf("line %v", pos.Line)
+	}
+
+	return fmt.Sprintf("%v:%v", pos.File, pos.Line)
+}
+
+// Rule is a pair of regular expression and its action.
+// Declarative rules (`pattern -> Token` and `pattern -> skip`) have no Go action code.
+type Rule struct {
+	Pos        Pos
+	Conditions []string // start conditions in `<COND>` prefix
+	Regex      string
+	Action     string
+	Token      string
+	Skip       bool
+
+	actionLine int // the number of lines between the pattern and the beginning of the action
+}
+
+// actionPos returns the position where the action begins.
+func (r Rule) actionPos() Pos {
+	return Pos{File: r.Pos.File, Line: r.Pos.Line + r.actionLine}
+}
+
+func (r Rule) IsDeclarative() bool {
+	return r.Token != "" || r.Skip
+}
+
 type Parser struct {
-	r *bufio.Reader
+	r        *bufio.Reader
+	filename string
+	origins  []Pos // original position of each line after %include is expanded
+	line     int   // the number of read lines
 }
 
 func NewParser(r *bufio.Reader) *Parser {
@@ -27,31 +103,237 @@ func NewParser(r *bufio.Reader) *Parser {
 	}
 }
 
-func (p *Parser) Parse() (def string, rules [][]string, userCode string) {
-	def, rulesStr, userCode := p.Split()
+// SetFilename sets the name of the file read by the parser.
+// It is used for diagnostics and %include is resolved relative to it.
+func (p *Parser) SetFilename(filename string) *Parser {
+	p.filename = filename
+	return p
+}
+
+func (p *Parser) Parse() (*Spec, error) {
+	if err := p.expandIncludes(); err != nil {
+		return nil, err
+	}
+
+	spec := &Spec{Config: NewConfig(), filename: p.filename}
+	if err := p.parseDefinitions(spec); err != nil {
+		return nil, err
+	}
+
+	explicitTokens := len(spec.Tokens) > 0
+	rulesLine := p.line + 1
+	rules, err := p.parseRules(p.readUntil(p.r, "%%\n"), rulesLine)
+	if err != nil {
+		return nil, err
+	}
+	if err := spec.checkConditions(rules); err != nil {
+		return nil, err
+	}
+	for _, rule := range rules {
+		if rule.Regex == eofRule {
+			spec.EOFRules = append(spec.EOFRules, rule)
+		} else {
+			spec.Rules = append(spec.Rules, rule)
+		}
+	}
+	if _, err := spec.eofRules(); err != nil {
+		return nil, err
+	}
+	if !explicitTokens {
+		// tokens of declarative rules are declared implicitly when there is no %token.
+		for _, rule := range rules {
+			if rule.Token != "" && !spec.hasToken(rule.Token) {
+				spec.addTokens(rule.Pos, []string{rule.Token})
+			}
+		}
+	}
+
+	var buf bytes.Buffer
+	io.Copy(&buf, p.r)
+	spec.UserCode = buf.String()
+	spec.userCodePos = p.pos(p.line + 1)
+
+	return spec, nil
+}
+
+func (p *Parser) expandIncludes() error {
+	var buf bytes.Buffer
+	if _, err := io.Copy(&buf, p.r); err != nil {
+		return err
+	}
+
+	inc := newIncluder()
+	if err := inc.expandFile(p.filename, buf.String()); err != nil {
+		return err
+	}
+	p.r = bufio.NewReader(strings.NewReader(inc.String()))
+	p.origins = inc.origins
+
+	return nil
+}
+
+// pos returns the original position of the line of expanded input.
+func (p *Parser) pos(line int) Pos {
+	if 0 < line && line <= len(p.origins) {
+		return p.origins[line-1]
+	}
+
+	return Pos{File: p.filename, Line: line}
+}
+
+// parseDefinitions reads the definitions section until the first `%%`.
+func (p *Parser) parseDefinitions(spec *Spec) error {
+	var def bytes.Buffer
+	for {
+		line, err := p.r.ReadString('\n')
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			return err
+		}
+		p.line++
+
+		if err := p.parseDefinitionLine(spec, &def, line); err != nil {
+			return newSpecError(p.pos(p.line), err)
+		}
+		if line == "%%\n" {
+			spec.Definition = def.String()
+			spec.rulesPos = p.pos(p.line)
+			return nil
+		}
+	}
+	spec.Definition = def.String()
+
+	return nil
+}
+
+func (p *Parser) parseDefinitionLine(spec *Spec, def *bytes.Buffer, line string) error {
+	switch {
+	case line == "%%\n":
+	case line == "%{\n":
+		begin := p.line + 1
+		code := p.readUntil(p.r, "%}\n")
+		for i := 0; i < strings.Count(code, "\n"); i++ {
+			spec.definitionOrigins = append(spec.definitionOrigins, p.pos(begin+i))
+		}
+		def.WriteString(code)
+	case strings.HasPrefix(line, "%option"):
+		opts, err := parseOptionLine(line)
+		if err != nil {
+			return err
+		}
+		if err := spec.Config.Apply(opts...); err != nil {
+			return err
+		}
+	case strings.HasPrefix(line, "%token"):
+		names, err := parseTokenLine(line)
+		if err != nil {
+			return err
+		}
+		if err := spec.addTokens(p.pos(p.line), names); err != nil {
+			return err
+		}
+	case strings.HasPrefix(line, "%s ") || strings.HasPrefix(line, "%x ") || line == "%s\n" || line == "%x\n":
+		conds, err := parseConditionLine(line)
+		if err != nil {
+			return err
+		}
+		if err := spec.addConditions(p.pos(p.line), conds); err != nil {
+			return err
+		}
+	case strings.HasPrefix(line, "%"):
+		return fmt.Errorf("%w: %v", ErrUnknownDirective, strings.TrimSpace(line))
+	}
 
-	return def, p.parseRules(rulesStr), userCode
+	return nil
 }
 
-func (p *Parser) parseRules(ruleStr string) [][]string {
+// parseRules parses rules section. line is the line number where the section begins.
+func (p *Parser) parseRules(ruleStr string, line int) ([]Rule, error) {
 	buf := bytes.NewBufferString(ruleStr)
-	rules := make([][]string, 0)
+	rules := make([]Rule, 0)
 	for {
 		if err := skipWhitespace(buf); err != nil {
 			if errors.Is(err, io.EOF) {
-				return rules
+				return rules, nil
 			}
-			panic(err)
+			return nil, err
+		}
+		consumed := len(ruleStr) - buf.Len()
+		pos := p.pos(line + strings.Count(ruleStr[:consumed], "\n"))
+		conds := readConditions(buf)
+		regex, err := p.readRule(buf)
+		if err != nil {
+			return nil, newSpecError(pos, err)
+		}
+		if err := skipBlank(buf); err != nil {
+			return nil, err
 		}
-		rule := p.readRule(buf)
+		if arrow, err := hasArrow(buf); err != nil {
+			return nil, err
+		} else if arrow {
+			rule, err := p.readDeclarativeRule(buf, regex)
+			if err != nil {
+				return nil, newSpecError(pos, err)
+			}
+			rule.Pos = pos
+			rule.Conditions = conds
+			rules = append(rules, rule)
+			continue
+		}
+		before := buf.Len()
 		blk := p.readBlock(buf)
-		if blk == "" {
-			break
+		// lines skipped before `{` of the action
+		skipped := ruleStr[len(ruleStr)-before : len(ruleStr)-buf.Len()-len(blk)]
+		if blk == "" || strings.TrimSpace(skipped) != "" {
+			return nil, newSpecError(pos, fmt.Errorf("%w: %v has no action", ErrInvalidRule, regex))
+		}
+		rules = append(rules, Rule{Pos: pos, Conditions: conds, Regex: regex, Action: blk, actionLine: strings.Count(skipped, "\n")})
+	}
+}
+
+// readDeclarativeRule reads the rest of `pattern -> Token` line.
+func (p *Parser) readDeclarativeRule(buf *bytes.Buffer, regex string) (Rule, error) {
+	line, err := buf.ReadString('\n')
+	if err != nil && !errors.Is(err, io.EOF) {
+		return Rule{}, err
+	}
+	target := strings.TrimSpace(line)
+	if target == skipRule {
+		return Rule{Regex: regex, Skip: true}, nil
+	}
+	if !token.IsIdentifier(target) {
+		return Rule{}, fmt.Errorf("%w: %v -> %q", ErrInvalidRule, regex, target)
+	}
+
+	return Rule{Regex: regex, Token: target}, nil
+}
+
+// skipBlank skips spaces and tabs in the same line.
+func skipBlank(reader io.RuneScanner) error {
+	for {
+		r, _, err := reader.ReadRune()
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				return nil
+			}
+			return err
 		}
-		rules = append(rules, []string{rule, blk})
+		if r != ' ' && r != '\t' {
+			return reader.UnreadRune()
+		}
+	}
+}
+
+// hasArrow reports whether `->` follows. It consumes `->` if exists.
+func hasArrow(buf *bytes.Buffer) (bool, error) {
+	if !bytes.HasPrefix(buf.Bytes(), []byte("->")) {
+		return false, nil
 	}
+	buf.Next(len("->"))
 
-	return rules
+	return true, nil
 }
 
 func skipWhitespace(reader io.RuneScanner) error {
@@ -73,29 +355,43 @@ func skipWhitespace(reader io.RuneScanner) error {
 	return nil
 }
 
-func (p *Parser) readRule(reader io.RuneScanner) string {
+// readRule reads the pattern of a rule, which ends with a blank out of bracket expressions,
+// the end of the line or a quoted string. `"` in a bracket expression is an ordinary rune.
+func (p *Parser) readRule(reader io.RuneScanner) (string, error) {
 	inRange := false
 	rs := make([]rune, 0)
 	for {
 		r, _, err := reader.ReadRune()
 		if err != nil {
-			panic(err)
+			if errors.Is(err, io.EOF) {
+				return string(rs), nil
+			}
+			return "", err
 		}
 
 		switch r {
 		case '"':
+			if inRange {
+				break
+			}
 			rs = append(rs, r)
 			for {
 				r, _, err = reader.ReadRune()
 				if err != nil {
-					panic(err)
+					if errors.Is(err, io.EOF) {
+						return "", fmt.Errorf("%w: unterminated string %v", ErrInvalidRule, string(rs))
+					}
+					return "", err
 				}
 
 				switch r {
 				case '\\':
 					nr, err := nextRune(reader)
 					if err != nil {
-						panic(err)
+						if errors.Is(err, io.EOF) {
+							return "", fmt.Errorf("%w: unterminated string %v", ErrInvalidRule, string(rs))
+						}
+						return "", err
 					}
 					reader.ReadRune()
 					if nr == '"' {
@@ -104,7 +400,9 @@ func (p *Parser) readRule(reader io.RuneScanner) string {
 					}
 				case '"':
 					rs = append(rs, r)
-					return string(rs)
+					return string(rs), nil
+				case '\n':
+					return "", fmt.Errorf("%w: unterminated string %v", ErrInvalidRule, string(rs))
 				default:
 					rs = append(rs, r)
 				}
@@ -115,10 +413,12 @@ func (p *Parser) readRule(reader io.RuneScanner) string {
 			inRange = false
 		case ' ':
 			if !inRange {
-				return string(rs)
+				return string(rs), nil
 			}
 		case '\t':
-			return string(rs)
+			return string(rs), nil
+		case '\n':
+			return string(rs), reader.UnreadRune()
 		}
 
 		rs = append(rs, r)
@@ -177,22 +477,6 @@ func (p *Parser) readBlock(reader io.RuneReader) string {
 	return ""
 }
 
-func (p *Parser) Split() (def string, rules string, userCode string) {
-
-	_ = p.readUntil(p.r, "%{\n")
-
-	def = p.readUntil(p.r, "%}\n")
-
-	_ = p.readUntil(p.r, "%%\n")
-	rules = p.readUntil(p.r, "%%\n")
-
-	var buf bytes.Buffer
-	io.Copy(&buf, p.r)
-	userCode = buf.String()
-
-	return
-}
-
 func (p *Parser) readUntil(r *bufio.Reader, delim string) string {
 	var buf bytes.Buffer
 	for {
@@ -204,6 +488,7 @@ func (p *Parser) readUntil(r *bufio.Reader, delim string) string {
 
 			panic(errors.New("error"))
 		}
+		p.line++
 		if s == delim {
 			return buf.String()
 		}
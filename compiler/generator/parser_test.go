@@ -4,9 +4,12 @@ import (
 	"bufio"
 	"bytes"
 	"fmt"
+	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/goropikari/tlex/compiler/generator"
+	"github.com/stretchr/testify/require"
 )
 
 func TestParser(t *testing.T) {
@@ -72,8 +75,524 @@ user defined code
 		t.Run(tt.name, func(t *testing.T) {
 			r := bytes.NewBufferString(tt.given)
 			p := generator.NewParser(bufio.NewReader(r))
-			def, rules, userCode := p.Parse()
-			fmt.Println(def, rules, userCode)
+			spec, err := p.Parse()
+			require.NoError(t, err)
+			fmt.Println(spec.Definition, spec.Rules, spec.UserCode)
+		})
+	}
+}
+
+func TestParser_Option(t *testing.T) {
+	tests := []struct {
+		name     string
+		given    string
+		expected generator.Config
+		err      error
+	}{
+		{
+			name: "default",
+			given: `
+%%
+a { }
+%%
+`,
+			expected: generator.NewConfig(),
+		},
+		{
+			name: "options",
+			given: `
+%option package=foo prefix="bar"
+%option caseless yylineno
+%{
+import "fmt"
+%}
+%option debug nodebug backend=goto table=map input=runereadseeker noline globaltext displaycolumn iter
+%option recover=sync sync=";\n" collecterrors
+%%
+a { }
+%%
+`,
+			expected: generator.Config{
+				PackageName:   "foo",
+				Prefix:        "bar",
+				Caseless:      true,
+				Yylineno:      true,
+				Debug:         false,
+				Line:          false,
+				GlobalText:    true,
+				DisplayColumn: true,
+				Iter:          true,
+				Backend:       generator.BackendGoto,
+				TableFormat:   generator.TableFormatMap,
+				Input:         generator.InputRuneReadSeeker,
+				Recover:       generator.RecoverSync,
+				SyncRunes:     ";\n",
+				CollectErrors: true,
+			},
+		},
+		{
+			name: "unknown option",
+			given: `
+%option hoge
+%%
+a { }
+%%
+`,
+			err: generator.ErrUnknownOption,
+		},
+		{
+			name: "invalid option value",
+			given: `
+%option table=hoge
+%%
+a { }
+%%
+`,
+			err: generator.ErrInvalidOption,
+		},
+		{
+			name: "quoted value with spaces",
+			given: `
+%option sync=" ;" prefix="lex"
+%%
+a { }
+%%
+`,
+			expected: generator.Config{
+				PackageName:   "main",
+				Prefix:        "lex",
+				Caseless:      false,
+				Yylineno:      false,
+				Debug:         false,
+				Line:          true,
+				GlobalText:    false,
+				DisplayColumn: false,
+				Iter:          false,
+				Backend:       generator.BackendTable,
+				TableFormat:   generator.TableFormatClass,
+				Input:         generator.InputReader,
+				Recover:       generator.RecoverRune,
+				SyncRunes:     " ;",
+				CollectErrors: false,
+			},
+		},
+		{
+			name: "unterminated quoted value",
+			given: `
+%option sync=" ;
+%%
+a { }
+%%
+`,
+			err: generator.ErrInvalidOption,
+		},
+		{
+			name: "invalid boolean value",
+			given: `
+%option caseless=hoge
+%%
+a { }
+%%
+`,
+			err: generator.ErrInvalidOption,
+		},
+		{
+			name: "unknown directive",
+			given: `
+%hoge
+%%
+a { }
+%%
+`,
+			err: generator.ErrUnknownDirective,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := bytes.NewBufferString(tt.given)
+			p := generator.NewParser(bufio.NewReader(r))
+			spec, err := p.Parse()
+			if tt.err != nil {
+				require.ErrorIs(t, err, tt.err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.expected, spec.Config)
+		})
+	}
+}
+
+func TestParser_Token(t *testing.T) {
+	tests := []struct {
+		name     string
+		given    string
+		expected []string
+		err      error
+	}{
+		{
+			name: "tokens",
+			given: `
+%token Keyword Identifier
+%token Digit
+%%
+a { return Keyword, nil }
+%%
+`,
+			expected: []string{"Keyword", "Identifier", "Digit"},
+		},
+		{
+			name: "duplicate token",
+			given: `
+%token Keyword Identifier Keyword
+%%
+a { return Keyword, nil }
+%%
+`,
+			err: generator.ErrDuplicateToken,
+		},
+		{
+			name: "invalid token name",
+			given: `
+%token 1abc
+%%
+a { }
+%%
+`,
+			err: generator.ErrInvalidToken,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := bytes.NewBufferString(tt.given)
+			p := generator.NewParser(bufio.NewReader(r))
+			spec, err := p.Parse()
+			if tt.err != nil {
+				require.ErrorIs(t, err, tt.err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.expected, spec.Tokens)
+		})
+	}
+}
+
+func TestParser_Rules(t *testing.T) {
+	tests := []struct {
+		name     string
+		given    string
+		expected []generator.Rule
+		tokens   []string
+		err      error
+		errLine  int
+	}{
+		{
+			name: "declarative rules",
+			given: `
+%%
+if|for -> Keyword
+[a-z][a-z]*	->	Identifier
+"->" -> Arrow
+[ \t\n]* -> skip
+"{" { return LBracket, nil }
+%%
+`,
+			expected: []generator.Rule{
+				{Pos: generator.Pos{Line: 3}, Regex: "if|for", Token: "Keyword"},
+				{Pos: generator.Pos{Line: 4}, Regex: "[a-z][a-z]*", Token: "Identifier"},
+				{Pos: generator.Pos{Line: 5}, Regex: `"->"`, Token: "Arrow"},
+				{Pos: generator.Pos{Line: 6}, Regex: `[ \t\n]*`, Skip: true},
+				{Pos: generator.Pos{Line: 7}, Regex: `"{"`, Action: "{ return LBracket, nil }"},
+			},
+			tokens: []string{"Keyword", "Identifier", "Arrow"},
+		},
+		{
+			name: "explicit tokens",
+			given: `
+%token Identifier Keyword
+%%
+if|for -> Keyword
+%%
+`,
+			expected: []generator.Rule{
+				{Pos: generator.Pos{Line: 4}, Regex: "if|for", Token: "Keyword"},
+			},
+			tokens: []string{"Identifier", "Keyword"},
+		},
+		{
+			name: "eof rule",
+			given: `
+%%
+a -> A
+<<EOF>> -> End
+%%
+`,
+			expected: []generator.Rule{
+				{Pos: generator.Pos{Line: 3}, Regex: "a", Token: "A"},
+			},
+			tokens: []string{"A", "End"},
+		},
+		{
+			name: "multiple eof rules",
+			given: `
+%%
+<<EOF>> -> End
+<<EOF>> { }
+%%
+`,
+			err: generator.ErrInvalidRule,
+		},
+		{
+			name: "invalid target",
+			given: `
+%%
+if|for -> 1Keyword
+%%
+`,
+			err: generator.ErrInvalidRule,
+		},
+		{
+			name: "quote in bracket expression",
+			given: `
+%%
+["] -> Quote
+[^"][^"]* { return Text, nil }
+%%
+`,
+			expected: []generator.Rule{
+				{Pos: generator.Pos{Line: 3}, Regex: `["]`, Token: "Quote"},
+				{Pos: generator.Pos{Line: 4}, Regex: `[^"][^"]*`, Action: "{ return Text, nil }"},
+			},
+			tokens: []string{"Quote"},
+		},
+		{
+			name: "unterminated string",
+			given: `
+%%
+a -> A
+"b -> B
+%%
+`,
+			err:     generator.ErrInvalidRule,
+			errLine: 4,
+		},
+		{
+			name: "no action at the end",
+			given: `
+%%
+a -> A
+b
+%%
+`,
+			err:     generator.ErrInvalidRule,
+			errLine: 4,
+		},
+		{
+			name: "no action before the next rule",
+			given: `
+%%
+a
+b { return B, nil }
+%%
+`,
+			err:     generator.ErrInvalidRule,
+			errLine: 3,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := bytes.NewBufferString(tt.given)
+			p := generator.NewParser(bufio.NewReader(r))
+			spec, err := p.Parse()
+			if tt.err != nil {
+				require.ErrorIs(t, err, tt.err)
+				if tt.errLine != 0 {
+					var serr *generator.SpecError
+					require.ErrorAs(t, err, &serr)
+					require.Equal(t, tt.errLine, serr.Pos.Line)
+				}
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.expected, spec.Rules)
+			require.Equal(t, tt.tokens, spec.Tokens)
+		})
+	}
+}
+
+func TestParser_Include(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"common/defs.l": `%token Keyword Identifier
+%{
+const x = 1
+%}
+`,
+		"common/rules.l": `%include "keywords.l"
+[a-z][a-z]* -> Identifier
+`,
+		"common/keywords.l": `select|from -> Keyword`,
+		"main.l": `%include "common/defs.l"
+%token Digit
+%%
+[0-9][0-9]* -> Digit
+%include "common/rules.l"
+. -> skip
+%%
+%include "not expanded in user code"
+`,
+		"cycle_a.l": `%include "cycle_b.l"
+%%
+%%
+`,
+		"cycle_b.l": `%include "cycle_a.l"
+`,
+		"bad.l": `%include "common/bad_defs.l"
+%%
+%%
+`,
+		"common/bad_defs.l": `%token A
+
+%hoge
+`,
+	}
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+		require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+	}
+
+	parse := func(name string) (*generator.Spec, error) {
+		path := filepath.Join(dir, name)
+		f, err := os.Open(path)
+		require.NoError(t, err)
+		defer f.Close()
+		return generator.NewParser(bufio.NewReader(f)).SetFilename(path).Parse()
+	}
+
+	t.Run("include", func(t *testing.T) {
+		spec, err := parse("main.l")
+		require.NoError(t, err)
+		require.Equal(t, []string{"Keyword", "Identifier", "Digit"}, spec.Tokens)
+		require.Equal(t, "const x = 1\n", spec.Definition)
+		require.Equal(t, []generator.Rule{
+			{Pos: generator.Pos{File: filepath.Join(dir, "main.l"), Line: 4}, Regex: "[0-9][0-9]*", Token: "Digit"},
+			{Pos: generator.Pos{File: filepath.Join(dir, "common/keywords.l"), Line: 1}, Regex: "select|from", Token: "Keyword"},
+			{Pos: generator.Pos{File: filepath.Join(dir, "common/rules.l"), Line: 2}, Regex: "[a-z][a-z]*", Token: "Identifier"},
+			{Pos: generator.Pos{File: filepath.Join(dir, "main.l"), Line: 6}, Regex: ".", Skip: true},
+		}, spec.Rules)
+		require.Equal(t, "%include \"not expanded in user code\"\n", spec.UserCode)
+	})
+
+	t.Run("cycle", func(t *testing.T) {
+		_, err := parse("cycle_a.l")
+		require.ErrorIs(t, err, generator.ErrIncludeCycle)
+		var specErr *generator.SpecError
+		require.ErrorAs(t, err, &specErr)
+		require.Equal(t, generator.Pos{File: filepath.Join(dir, "cycle_b.l"), Line: 1}, specErr.Pos)
+	})
+
+	t.Run("error in included file", func(t *testing.T) {
+		_, err := parse("bad.l")
+		require.ErrorIs(t, err, generator.ErrUnknownDirective)
+		var specErr *generator.SpecError
+		require.ErrorAs(t, err, &specErr)
+		require.Equal(t, generator.Pos{File: filepath.Join(dir, "common/bad_defs.l"), Line: 3}, specErr.Pos)
+	})
+}
+
+func TestParser_Condition(t *testing.T) {
+	tests := []struct {
+		name       string
+		given      string
+		conditions []generator.Condition
+		rules      []generator.Rule
+		err        error
+	}{
+		{
+			name: "conditions",
+			given: `
+%s INC
+%x STR COMMENT
+%%
+a -> A
+<STR>b -> B
+<INC, COMMENT>c -> C
+<*>d -> D
+<STR><<EOF>> { }
+<<EOF>> { }
+%%
+`,
+			conditions: []generator.Condition{
+				{Name: "INC"},
+				{Name: "STR", Exclusive: true},
+				{Name: "COMMENT", Exclusive: true},
+			},
+			rules: []generator.Rule{
+				{Pos: generator.Pos{Line: 5}, Regex: "a", Token: "A"},
+				{Pos: generator.Pos{Line: 6}, Conditions: []string{"STR"}, Regex: "b", Token: "B"},
+				{Pos: generator.Pos{Line: 7}, Conditions: []string{"INC", "COMMENT"}, Regex: "c", Token: "C"},
+				{Pos: generator.Pos{Line: 8}, Conditions: []string{"*"}, Regex: "d", Token: "D"},
+			},
+		},
+		{
+			name: "less than is not a condition",
+			given: `
+%%
+<= -> LE
+%%
+`,
+			rules: []generator.Rule{
+				{Pos: generator.Pos{Line: 3}, Regex: "<=", Token: "LE"},
+			},
+		},
+		{
+			name: "undeclared condition",
+			given: `
+%x STR
+%%
+<STRING>a -> A
+%%
+`,
+			err: generator.ErrUndeclaredCondition,
+		},
+		{
+			name: "duplicate condition",
+			given: `
+%x STR
+%s STR
+%%
+%%
+`,
+			err: generator.ErrInvalidCondition,
+		},
+		{
+			name: "multiple eof rules for a condition",
+			given: `
+%x STR
+%%
+<STR><<EOF>> { }
+<*><<EOF>> { }
+%%
+`,
+			err: generator.ErrInvalidRule,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := bytes.NewBufferString(tt.given)
+			p := generator.NewParser(bufio.NewReader(r))
+			spec, err := p.Parse()
+			if tt.err != nil {
+				require.ErrorIs(t, err, tt.err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.conditions, spec.Conditions)
+			require.Equal(t, tt.rules, spec.Rules)
 		})
 	}
 }
@@ -0,0 +1,145 @@
+package generator
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/goropikari/tlex/automata"
+)
+
+// maxWitnesses is the maximum number of witness strings reported for a rule.
+const maxWitnesses = 3
+
+// ShadowedRule is a rule which loses some strings it matches to earlier rules.
+// A rule is never matched if it loses all of them.
+type ShadowedRule struct {
+	Index     int // index of the rule in Spec.Rules
+	Rule      Rule
+	Never     bool      // the rule is never matched
+	Witnesses []Witness // shortest strings which the rule matches but loses
+}
+
+// Witness is a string which an earlier rule wins.
+type Witness struct {
+	Text        string
+	Condition   string // start condition in which the text is scanned
+	WinnerIndex int    // index of the rule matching the text in Spec.Rules
+	Winner      Rule
+}
+
+func (sr ShadowedRule) String() string {
+	var buf strings.Builder
+	if sr.Never {
+		buf.WriteString(fmt.Sprintf("%v: rule %v can never be matched", sr.Rule.Pos, sr.Rule.Regex))
+	} else {
+		buf.WriteString(fmt.Sprintf("%v: rule %v is partially shadowed", sr.Rule.Pos, sr.Rule.Regex))
+	}
+	for i, w := range sr.Witnesses {
+		if i == 0 {
+			buf.WriteString(": ")
+		} else {
+			buf.WriteString(", ")
+		}
+		buf.WriteString(fmt.Sprintf("%q is matched by %v (%v)", w.Text, w.Winner.Regex, w.Winner.Pos))
+		if w.Condition != initialCondition {
+			buf.WriteString(fmt.Sprintf(" in %v", w.Condition))
+		}
+	}
+
+	return buf.String()
+}
+
+// FindShadowedRules reports rules which lose strings they match to earlier rules.
+// Rules which are never matched come with Never.
+// Since the lexer never accepts the empty string, it is not taken into account.
+func FindShadowedRules(spec *Spec) []ShadowedRule {
+	if len(spec.Rules) == 0 {
+		return nil
+	}
+	regexs := make([]string, 0, len(spec.Rules))
+	for _, rule := range spec.Rules {
+		regexs = append(regexs, rule.Regex)
+	}
+	// the DFA isn't minimized to keep all regexs accepting each state.
+	dfa := lexerNFA(regexs, spec.activeRules(), spec.Config.Caseless).ToImdNFA().ToDFA()
+
+	conds := spec.conditions()
+	matched := make([]bool, len(spec.Rules))
+	witnesses := make([][]Witness, len(spec.Rules))
+	for c, init := range dfa.GetInitStates() {
+		for _, p := range shortestPaths(dfa, init) {
+			ids := dfa.GetRegexIDs(p.state)
+			if len(ids) == 0 {
+				continue
+			}
+			winner := int(ids[0]) - 1
+			matched[winner] = true
+			for _, id := range ids[1:] {
+				i := int(id) - 1
+				if len(witnesses[i]) < maxWitnesses && !hasWitness(witnesses[i], p.text) {
+					witnesses[i] = append(witnesses[i], Witness{
+						Text:        p.text,
+						Condition:   conds[c].Name,
+						WinnerIndex: winner,
+						Winner:      spec.Rules[winner],
+					})
+				}
+			}
+		}
+	}
+
+	shadowed := make([]ShadowedRule, 0)
+	for i, rule := range spec.Rules {
+		if matched[i] && len(witnesses[i]) == 0 {
+			continue
+		}
+		shadowed = append(shadowed, ShadowedRule{
+			Index:     i,
+			Rule:      rule,
+			Never:     !matched[i],
+			Witnesses: witnesses[i],
+		})
+	}
+
+	return shadowed
+}
+
+func hasWitness(ws []Witness, text string) bool {
+	for _, w := range ws {
+		if w.Text == text {
+			return true
+		}
+	}
+
+	return false
+}
+
+type statePath struct {
+	state automata.StateID
+	text  string
+}
+
+// shortestPaths returns states reachable from init by one or more runes with the shortest string
+// reaching each of them, in ascending order of the length.
+func shortestPaths(dfa *automata.DFA, init automata.StateID) []statePath {
+	visited := map[automata.StateID]bool{init: true}
+	paths := make([]statePath, 0)
+	queue := []statePath{{state: init}}
+	for len(queue) > 0 {
+		p := queue[0]
+		queue = queue[1:]
+
+		for _, intv := range dfa.GetTransitionTable().Intervals(p.state) {
+			to, _ := dfa.GetTransitionTable().Get(p.state, intv)
+			if visited[to] {
+				continue
+			}
+			visited[to] = true
+			np := statePath{state: to, text: p.text + string(intv.Sample())}
+			paths = append(paths, np)
+			queue = append(queue, np)
+		}
+	}
+
+	return paths
+}
@@ -12,40 +12,61 @@ import (
 
 {{ .EmbeddedTmpl }}
 
-type yyStateID = int
-type yyRegexID = int
-var YYText string
+{{ .TokenKindTmpl }}
+
+{{ .ConditionsTmpl }}
+
+type {{ .Prefix }}StateID = int
+type {{ .Prefix }}RegexID = int
+{{- if .GlobalText }}
+var {{ .UpperPrefix }}Text string
+{{- end }}
 
 var (
-	ErrYYScan = errors.New("failed to scan")
+	Err{{ .UpperPrefix }}Scan          = errors.New("failed to scan")
+	Err{{ .UpperPrefix }}EmptyStack    = errors.New("start condition stack is empty")
+	Err{{ .UpperPrefix }}NegativeCount = errors.New("negative count")
+	Err{{ .UpperPrefix }}LongCount     = errors.New("count exceeds the current token")
 )
 
+{{- if eq .Recover "sync" }}
+// {{ .Prefix }}SyncRunes is the synchronizing runes. Runes no rule matches are skipped until one of them.
+const {{ .Prefix }}SyncRunes = {{ printf "%q" .SyncRunes }}
+{{ end }}
+
+// start state of each start condition
+var {{ .Prefix }}StartStates = []{{ .Prefix }}StateID{
+	{{ .StartStatesTmpl }}
+}
+
+{{ if ne .Backend "goto" }}
 // state id to regex id
-var yyStateIDToRegexID = []yyRegexID{
+var {{ .Prefix }}StateIDToRegexID = []{{ .Prefix }}RegexID{
 	0, // state 0 is dead state
 	{{ .StateIDToRegexIDTmpl }}
 }
 
-var yyFinStates = map[yyStateID]struct{}{
+{{ if eq .TableFormat "map" }}
+var {{ .Prefix }}FinStates = map[{{ .Prefix }}StateID]struct{}{
 	{{ .FinStatesTmpl }}
 }
 
-type yyinterval struct {
+type {{ .Prefix }}interval struct {
 	l int
 	r int
 }
 
-func (x yyinterval) overlap(y yyinterval) bool {
+func (x {{ .Prefix }}interval) overlap(y {{ .Prefix }}interval) bool {
 	return y.l <= x.r && x.l <= y.r
 }
 
-var yyTransitionTable = map[yyStateID]map[yyinterval]yyStateID{
+var {{ .Prefix }}TransitionTable = map[{{ .Prefix }}StateID]map[{{ .Prefix }}interval]{{ .Prefix }}StateID{
 	{{ .TransitionTableTmpl }}
 }
 
-func yyNextStep(id yyStateID, r rune) yyStateID {
-	if mp, ok := yyTransitionTable[id]; ok {
-		t := yyinterval{l: int(r), r: int(r)}
+func {{ .Prefix }}NextStep(id {{ .Prefix }}StateID, r rune) {{ .Prefix }}StateID {
+	if mp, ok := {{ .Prefix }}TransitionTable[id]; ok {
+		t := {{ .Prefix }}interval{l: int(r), r: int(r)}
 		for intv, sid := range mp {
 			if intv.overlap(t) {
 				return sid
@@ -56,105 +77,897 @@ func yyNextStep(id yyStateID, r rune) yyStateID {
 	return 0
 }
 
-type yyLexer struct {
-	rs          RuneReadSeeker
-	beginPos    int
-	finPos      int
-	currPos     int
+func {{ .Prefix }}IsFinState(id {{ .Prefix }}StateID) bool {
+	_, ok := {{ .Prefix }}FinStates[id]
+	return ok
+}
+{{- else }}
+var {{ .Prefix }}FinStates = []bool{
+	{{ .FinStatesTmpl }}
+}
+
+{{ if eq .TableFormat "sorted" }}
+// {{ .Prefix }}transition is a transition by runes in [l, r].
+type {{ .Prefix }}transition struct {
+	l, r int32
+	to   {{ .Prefix }}StateID
+}
+
+// transitions of state s are {{ .Prefix }}Transitions[{{ .Prefix }}TransitionOffsets[s]:{{ .Prefix }}TransitionOffsets[s+1]]
+// in ascending order of runes.
+var {{ .Prefix }}Transitions = []{{ .Prefix }}transition{
+	{{ .TransitionTableTmpl }}
+}
+
+var {{ .Prefix }}TransitionOffsets = []int{
+	{{ .TransitionOffsetTmpl }}
+}
+
+func {{ .Prefix }}NextStep(id {{ .Prefix }}StateID, r rune) {{ .Prefix }}StateID {
+	trans := {{ .Prefix }}Transitions[{{ .Prefix }}TransitionOffsets[id]:{{ .Prefix }}TransitionOffsets[id+1]]
+	// binary search for the first transition whose upper bound is not less than r
+	i, j := 0, len(trans)
+	for i < j {
+		h := int(uint(i+j) >> 1)
+		if trans[h].r < r {
+			i = h + 1
+		} else {
+			j = h
+		}
+	}
+	if i < len(trans) && trans[i].l <= r {
+		return trans[i].to
+	}
+
+	return 0
+}
+{{- else }}
+// class of each rune less than 256. class 0 has no transition.
+var {{ .Prefix }}ByteClasses = [256]int32{
+	{{ .ByteClassesTmpl }}
+}
+
+// {{ .Prefix }}classRange is the class of runes in [l, r].
+type {{ .Prefix }}classRange struct {
+	l, r  int32
+	class int32
+}
+
+// classes of runes not less than 256 in ascending order of runes
+var {{ .Prefix }}ClassRanges = []{{ .Prefix }}classRange{
+	{{ .ClassRangesTmpl }}
+}
+
+const {{ .Prefix }}NumClasses = {{ .NumClasses }}
+
+// {{ .Prefix }}Transitions[s*{{ .Prefix }}NumClasses+c] is the next state of state s by a rune of class c.
+var {{ .Prefix }}Transitions = []{{ .Prefix }}StateID{
+	{{ .TransitionTableTmpl }}
+}
+
+func {{ .Prefix }}ClassOf(r rune) int32 {
+	if 0 <= r && r < 256 {
+		return {{ .Prefix }}ByteClasses[r]
+	}
+	i, j := 0, len({{ .Prefix }}ClassRanges)
+	for i < j {
+		h := int(uint(i+j) >> 1)
+		if {{ .Prefix }}ClassRanges[h].r < r {
+			i = h + 1
+		} else {
+			j = h
+		}
+	}
+	if i < len({{ .Prefix }}ClassRanges) && {{ .Prefix }}ClassRanges[i].l <= r {
+		return {{ .Prefix }}ClassRanges[i].class
+	}
+
+	return 0
+}
+
+func {{ .Prefix }}NextStep(id {{ .Prefix }}StateID, r rune) {{ .Prefix }}StateID {
+	return {{ .Prefix }}Transitions[id*{{ .Prefix }}NumClasses+int({{ .Prefix }}ClassOf(r))]
+}
+{{- end }}
+
+func {{ .Prefix }}IsFinState(id {{ .Prefix }}StateID) bool {
+	return {{ .Prefix }}FinStates[id]
+}
+{{- end }}
+{{- end }}
+
+type {{ .LexerName }} struct {
+	in          {{ .Prefix }}Input
+	beginPos    int // position of the current token in in.buf
+	finPos      int // end position of the longest match in in.buf
+	currPos     int // read position in in.buf
 	finRegexID  int
-	currStateID yyStateID
+	currStateID {{ .Prefix }}StateID
+	cond        int
+	condStack   []int
+	inputStack  []{{ .Prefix }}Input
+	inputID     int
+	textPos     int  // position of the text of the current token in in.buf
+	more        bool // the next token is appended to the current text
+	text        []byte
+	start       {{ .PublicPrefix }}Position // position of the current token
+	startCR     bool                        // in.cr at start
+	end         {{ .PublicPrefix }}Position // position just after the current token
 	YYText      string
+{{- if .Yylineno }}
+	YYLineno    int // line number at the end of the current token
+{{- end }}
+{{- if .DisplayColumn }}
+	TabWidth    int // distance of tab stops of display columns
+{{- end }}
+{{- if eq .Recover "echo" }}
+	Out         io.Writer // destination of runes no rule matches, os.Stdout by default
+{{- else }}
+	// OnError is called with a scan error after the runes no rule matches are skipped.
+	// Lexing continues if it returns nil, and the returned error is reported otherwise.
+	OnError     func(err error) error
+{{- end }}
+{{- if .CollectErrors }}
+	Errors      []error // scan errors collected instead of being returned by Next
+{{- end }}
 }
-
-type RuneReadSeeker interface {
+{{ if eq .Input "runereadseeker" }}
+type {{ .PublicPrefix }}RuneReadSeeker interface {
 	io.ReadSeeker
 	io.RuneScanner
 }
+{{ end }}
+// {{ .Prefix }}BufSize is the initial size of the input buffer.
+// The buffer grows when a token does not fit in it.
+const {{ .Prefix }}BufSize = 4096
+
+// {{ .PublicPrefix }}Position is a position in the input.
+// A line ends with LF, CR or CRLF, and CRLF is one line break.
+type {{ .PublicPrefix }}Position struct {
+	Offset     int // byte offset, starting at 0
+	Line       int // line number, starting at 1
+	Column     int // column in runes, starting at 1
+	ByteColumn int // column in bytes, starting at 1
+{{- if .DisplayColumn }}
+	// column in terminal cells, starting at 1. wide runes occupy two cells and tabs move to the next tab stop.
+	DisplayColumn int
+{{- end }}
+}
+
+// {{ .Prefix }}StartPosition is the position of the beginning of an input.
+{{- if .DisplayColumn }}
+var {{ .Prefix }}StartPosition = {{ .PublicPrefix }}Position{Line: 1, Column: 1, ByteColumn: 1, DisplayColumn: 1}
+{{- else }}
+var {{ .Prefix }}StartPosition = {{ .PublicPrefix }}Position{Line: 1, Column: 1, ByteColumn: 1}
+{{- end }}
+
+func (p {{ .PublicPrefix }}Position) String() string {
+	return fmt.Sprintf("%d:%d", p.Line, p.Column)
+}
 
-func New(rs RuneReadSeeker) *yyLexer {
-	return &yyLexer{
-		rs:          rs,
+// {{ .PublicPrefix }}Token is a token returned by NextToken.
+// Unlike YYText, Text is not overwritten by later tokens.
+// With NewFromBytes, Text shares the memory with the input as YYText.
+type {{ .PublicPrefix }}Token struct {
+	Kind  {{ .KindType }}
+	Text  string
+	Start {{ .PublicPrefix }}Position // position of the beginning of the token
+	End   {{ .PublicPrefix }}Position // position just after the token
+}
+
+// {{ .PublicPrefix }}ScanError is the error of runes no rule matches.
+// It wraps Err{{ .UpperPrefix }}Scan.
+type {{ .PublicPrefix }}ScanError struct {
+	Pos       {{ .PublicPrefix }}Position // position of the skipped runes
+	Text      string   // skipped runes
+	Partial   string   // text read from Pos before the lexer got stuck, which no rule matches
+	Rune      rune     // rune no rule can continue with after Partial, or -1 at the end of the input
+	Condition int      // start condition
+}
+
+func (e *{{ .PublicPrefix }}ScanError) Error() string {
+	msg := fmt.Sprintf("%v: %v %q", e.Pos, Err{{ .UpperPrefix }}Scan, e.Text)
+	if e.Condition != 0 {
+		msg += " in start condition " + {{ .Prefix }}ConditionNames[e.Condition]
+	}
+
+	return msg
+}
+
+func (e *{{ .PublicPrefix }}ScanError) Unwrap() error {
+	return Err{{ .UpperPrefix }}Scan
+}
+
+// {{ .Prefix }}Input is an input and the buffer of bytes read from it.
+// Bytes before the current token are discarded when the buffer is refilled,
+// so the buffer size is bounded by the longest token.
+// An in-memory input has no reader and its buffer is the whole input, which is never modified.
+type {{ .Prefix }}Input struct {
+	r   io.Reader
+	buf []byte
+	err error // error of the last read, which is reported after buf is consumed
+	pos int   // read position of a suspended input
+
+	position {{ .PublicPrefix }}Position // position of the beginning of the current token
+	cr       bool                        // the last byte before position is CR
+}
+
+// advance moves the position over b.
+{{- if .DisplayColumn }}
+// Tab stops of the display column are every tabWidth columns.
+func (in *{{ .Prefix }}Input) advance(b []byte, tabWidth int) {
+{{- else }}
+func (in *{{ .Prefix }}Input) advance(b []byte) {
+{{- end }}
+	p := &in.position
+	p.Offset += len(b)
+	for {{ if .DisplayColumn }}i{{ else }}_{{ end }}, c := range b {
+		switch {
+		case c == '\n' && in.cr:
+			// LF of CRLF. the line break is counted at CR.
+		case c == '\n' || c == '\r':
+			p.Line++
+			p.Column = 1
+			p.ByteColumn = 1
+{{- if .DisplayColumn }}
+			p.DisplayColumn = 1
+{{- end }}
+		default:
+			p.ByteColumn++
+			if utf8.RuneStart(c) {
+				p.Column++
+{{- if .DisplayColumn }}
+				r, _ := utf8.DecodeRune(b[i:])
+				p.DisplayColumn = {{ .Prefix }}NextDisplayColumn(p.DisplayColumn, r, tabWidth)
+{{- end }}
+			}
+		}
+		in.cr = c == '\r'
+	}
+}
+
+func New{{ .PublicPrefix }}(r {{ .InputType }}) *{{ .LexerName }} {
+	return {{ .Prefix }}NewLexer({{ .Prefix }}Input{r: r})
+}
+
+// New{{ .PublicPrefix }}FromBytes returns a lexer scanning b directly.
+// Bytes(), Text(), YYText and Text of tokens share the memory with b, and these strings outlive lexing.
+// b must not be modified while the lexer or any text returned by it is in use.
+func New{{ .PublicPrefix }}FromBytes(b []byte) *{{ .LexerName }} {
+	return {{ .Prefix }}NewLexer({{ .Prefix }}Input{buf: b, err: io.EOF})
+}
+
+// New{{ .PublicPrefix }}FromString returns a lexer scanning s without copying it.
+func New{{ .PublicPrefix }}FromString(s string) *{{ .LexerName }} {
+	return {{ .Prefix }}NewLexer({{ .Prefix }}Input{buf: {{ .Prefix }}StringBytes(s), err: io.EOF})
+}
+
+func {{ .Prefix }}NewLexer(in {{ .Prefix }}Input) *{{ .LexerName }} {
+	in.position = {{ .Prefix }}StartPosition
+	return &{{ .LexerName }}{
+		in:          in,
 		beginPos:    0,
 		finPos:      0,
 		currPos:     0,
 		finRegexID:  0,
-		currStateID: 1, // init state id is 1.
+		currStateID: {{ .Prefix }}StartStates[0],
+		start:       in.position,
+		end:         in.position,
+		cond:        0,
+{{- if .Yylineno }}
+		YYLineno:    1,
+{{- end }}
+{{- if .DisplayColumn }}
+		TabWidth:    {{ .Prefix }}DefaultTabWidth,
+{{- end }}
+{{- if eq .Recover "echo" }}
+		Out:         os.Stdout,
+{{- end }}
 	}
 }
 
-func (yylex *yyLexer) currRune() (rune, int, error) {
-	ru, size, err := yylex.rs.ReadRune()
-	if err != nil {
-		return 0, 0, err
+// {{ .Prefix }}StringBytes returns the bytes of s without copying. They must not be modified.
+func {{ .Prefix }}StringBytes(s string) []byte {
+	return *(*[]byte)(unsafe.Pointer(&struct {
+		string
+		int
+	}{s, len(s)}))
+}
+
+// Bytes returns the text of the current token.
+// It is a sub-slice of the input buffer and valid until the next call of Next.
+// PeekRune, ReadRune, ReadUntil and Consume may refill the buffer and invalidate it as well.
+func (yylex *{{ .LexerName }}) Bytes() []byte {
+	return yylex.text
+}
+
+// Pos returns the position of the beginning of the current token.
+func (yylex *{{ .LexerName }}) Pos() {{ .PublicPrefix }}Position {
+	return yylex.start
+}
+
+// EndPos returns the position just after the current token.
+func (yylex *{{ .LexerName }}) EndPos() {{ .PublicPrefix }}Position {
+	return yylex.end
+}
+
+// Text returns the text of the current token. It is the same as YYText.
+func (yylex *{{ .LexerName }}) Text() string {
+	return yylex.YYText
+}
+
+// textString returns b as a string.
+// It shares the memory with an in-memory input, which the caller keeps unchanged while the text is in use.
+func (yylex *{{ .LexerName }}) textString(b []byte) string {
+	if yylex.in.r == nil {
+		return *(*string)(unsafe.Pointer(&b))
 	}
-	if err := yylex.rs.UnreadRune(); err != nil {
-		return 0, 0, err
+
+	return string(b)
+}
+
+// fill reads more bytes from the input after discarding bytes before the current token.
+func (yylex *{{ .LexerName }}) fill() error {
+	in := &yylex.in
+	if in.err != nil {
+		return in.err
+	}
+	if keep := yylex.textPos; keep > 0 {
+		n := copy(in.buf, in.buf[keep:])
+		in.buf = in.buf[:n]
+		yylex.textPos = 0
+		yylex.beginPos -= keep
+		yylex.finPos -= keep
+		yylex.currPos -= keep
+	}
+	if len(in.buf) == cap(in.buf) {
+		buf := make([]byte, len(in.buf), 2*cap(in.buf)+{{ .Prefix }}BufSize)
+		copy(buf, in.buf)
+		in.buf = buf
+	}
+	for i := 0; i < 100; i++ {
+		n, err := in.r.Read(in.buf[len(in.buf):cap(in.buf)])
+		in.buf = in.buf[:len(in.buf)+n]
+		if err != nil {
+			in.err = err
+		}
+		if n > 0 {
+			return nil
+		} else if err != nil {
+			return err
+		}
+	}
+	in.err = io.ErrNoProgress
+
+	return in.err
+}
+
+// currRune returns the rune at the read position without consuming it.
+// An incomplete UTF-8 sequence at the end of the input is utf8.RuneError of size 1.
+func (yylex *{{ .LexerName }}) currRune() (rune, int, error) {
+	for !utf8.FullRune(yylex.in.buf[yylex.currPos:]) {
+		if err := yylex.fill(); err != nil {
+			if yylex.currPos < len(yylex.in.buf) {
+				break
+			}
+			return 0, 0, err
+		}
 	}
+	ru, size := utf8.DecodeRune(yylex.in.buf[yylex.currPos:])
+
 	return ru, size, nil
 }
 
-func (yylex *yyLexer) Next() (int, error) {
-	yyEofCnt := 0
-yystart:
-	for  {
+{{ if eq .Backend "goto" -}}
+// scan runs the DFA from the start state while transitions exist.
+// Each state is a labeled block which jumps to the next state by goto.
+// It returns false if the input is exhausted at the beginning of a token.
+func (yylex *{{ .LexerName }}) scan() (bool, error) {
+	var (
+		yyr    rune
+		yysize int
+		err    error
+	)
+	{{ .DirectScanTmpl }}
+}
+{{- else -}}
+// scan runs the DFA from the current state while transitions exist.
+// It returns false if the input is exhausted at the beginning of a token.
+func (yylex *{{ .LexerName }}) scan() (bool, error) {
+	for {
 		yyr, yysize, err := yylex.currRune()
 		if err != nil {
-			if errors.Is(err, io.EOF) {
-				yyEofCnt++
-				if yyEofCnt == 1 {
-					goto finProcess
-				}
-			}
-			return 0, err
+			return yylex.scanEnd(err)
 		}
-	finProcess:
-		yyNxStID := yyNextStep(yylex.currStateID, yyr)
+		yyNxStID := {{ .Prefix }}NextStep(yylex.currStateID, yyr)
 		if yyNxStID == 0 {
-			if _, err := yylex.rs.Seek(int64(yylex.finPos), io.SeekStart); err != nil {
-				return 0, err
-			}
-			_, lastSize, err := yylex.currRune()
-			if err != nil {
-				return 0, err
-			}
-			yydata := make([]byte, yylex.finPos+lastSize-yylex.beginPos)
-			if _, err := yylex.rs.Seek(int64(yylex.beginPos), io.SeekStart); err != nil {
+			return true, nil
+		}
+		yylex.currStateID = yyNxStID
+		yylex.currPos += yysize
+		if {{ .Prefix }}IsFinState(yyNxStID) {
+			yylex.finPos = yylex.currPos
+			yylex.finRegexID = {{ .Prefix }}StateIDToRegexID[yyNxStID]
+		}
+	}
+}
+{{- end }}
+
+// scanEnd returns the result of scan when reading a rune fails by err.
+func (yylex *{{ .LexerName }}) scanEnd(err error) (bool, error) {
+	if !errors.Is(err, io.EOF) {
+		return false, err
+	}
+
+	return yylex.currPos != yylex.beginPos, nil
+}
+
+func (yylex *{{ .LexerName }}) Next() ({{ .KindType }}, error) {
+yystart:
+	for {
+		if !yylex.more {
+			yylex.textPos = yylex.beginPos
+		}
+		yymatched, err := yylex.scan()
+		if err != nil {
+			return 0, err
+		} else if !yymatched {
+			// input is exhausted
+			break
+		}
+{{- if ne .Recover "echo" }}
+		var yyerr *{{ .PublicPrefix }}ScanError
+{{- end }}
+		if yylex.finRegexID == 0 {
+			// no rule matched, and the first rune is consumed. the text appended by More is dropped.
+			yylex.more = false
+{{- if ne .Recover "echo" }}
+			yyerr = yylex.scanError()
+{{- end }}
+			_, yysize := utf8.DecodeRune(yylex.in.buf[yylex.beginPos:])
+			yylex.finPos = yylex.beginPos + yysize
+{{- if eq .Recover "sync" }}
+			yylex.skipToSync()
+{{- end }}
+		}
+		if !yylex.more {
+			yylex.textPos = yylex.beginPos
+			yylex.start = yylex.in.position
+			yylex.startCR = yylex.in.cr
+		}
+		yylex.more = false
+		yylex.text = yylex.in.buf[yylex.textPos:yylex.finPos]
+		yylex.YYText = yylex.textString(yylex.text)
+{{- if .GlobalText }}
+		{{ .UpperPrefix }}Text = yylex.YYText
+{{- end }}
+{{- if .DisplayColumn }}
+		yylex.in.advance(yylex.in.buf[yylex.beginPos:yylex.finPos], yylex.TabWidth)
+{{- else }}
+		yylex.in.advance(yylex.in.buf[yylex.beginPos:yylex.finPos])
+{{- end }}
+		yylex.end = yylex.in.position
+{{- if .Yylineno }}
+		yylex.YYLineno = yylex.end.Line
+{{- end }}
+		yylex.beginPos = yylex.finPos
+		yylex.currPos = yylex.finPos
+		yylex.currStateID = {{ .Prefix }}StartStates[yylex.cond]
+
+		regexID := yylex.finRegexID
+		yylex.finRegexID = 0
+{{- if .Debug }}
+		if regexID == 0 {
+			fmt.Fprintf(os.Stderr, "--no rule matched (%q)\n", yylex.YYText)
+		} else {
+			fmt.Fprintf(os.Stderr, "--accepting rule %d (%q)\n", regexID, yylex.YYText)
+		}
+{{- end }}
+		switch regexID {
+		case 0:
+{{- if eq .Recover "echo" }}
+			if err := yylex.Echo(); err != nil {
 				return 0, err
 			}
-			if _, err := yylex.rs.Read(yydata); err != nil {
+{{- else }}
+			yyerr.Text = yylex.YYText
+			if err := yylex.recoverError(yyerr); err != nil {
 				return 0, err
 			}
-			yylex.YYText = string(yydata)
-			YYText = yylex.YYText
-			yyNewCurrPos := yylex.finPos + lastSize
-			yylex.beginPos = yyNewCurrPos
-			yylex.finPos = yyNewCurrPos
-			yylex.currPos = yyNewCurrPos
-			yylex.currStateID = 1
-
-			regexID := yylex.finRegexID
-			yylex.finRegexID = 0
-			switch regexID {
-			case 0:
-				return 0, ErrYYScan
-			{{ .RegexActionsTmpl }}
-			default:
-				return 0, ErrYYScan
-			}
+{{- end }}
+		{{ .RegexActionsTmpl }}
+		default:
+			return 0, Err{{ .UpperPrefix }}Scan
 		}
-		if _, ok := yyFinStates[yyNxStID]; ok {
-			yylex.finPos = yylex.currPos
-			yylex.finRegexID = yyStateIDToRegexID[yyNxStID]
+	}
+
+	yylex.more = false
+	yylex.text = nil
+	yylex.YYText = ""
+	yylex.start = yylex.in.position
+	yylex.end = yylex.in.position
+{{- if .GlobalText }}
+	{{ .UpperPrefix }}Text = ""
+{{- end }}
+{{- if .Debug }}
+	fmt.Fprintln(os.Stderr, "--EOF")
+{{- end }}
+{{- if .EOFActionTmpl }}
+	yyInputID := yylex.inputID
+{{ .EOFActionTmpl }}
+{{- if not .EOFActionTerminates }}
+	if yylex.inputID != yyInputID {
+		// the action switched the input
+		goto yystart
+	}
+{{- end }}
+{{- end }}
+{{- if not .EOFActionTerminates }}
+	if yylex.PopInput() {
+		goto yystart
+	}
+
+	return 0, io.EOF
+{{- end }}
+}
+
+{{ if eq .Recover "echo" -}}
+// Echo writes the current token to Out. It corresponds to ECHO of lex.
+// Runes no rule matches are written by Echo.
+func (yylex *{{ .LexerName }}) Echo() error {
+	_, err := yylex.Out.Write(yylex.text)
+	return err
+}
+{{- else -}}
+{{ if eq .Recover "sync" -}}
+// skipToSync extends the runes no rule matches to just before the next synchronizing rune.
+// A read error stops skipping, and it is returned by the next scan.
+func (yylex *{{ .LexerName }}) skipToSync() {
+	yylex.currPos = yylex.finPos
+	for {
+		r, size, err := yylex.currRune()
+		if err != nil || strings.ContainsRune({{ .Prefix }}SyncRunes, r) {
+			break
 		}
-		yylex.currStateID = yyNxStID
-		yylex.currPos+=yysize
-		if _, err := yylex.rs.Seek(int64(yylex.currPos), io.SeekStart); err != nil {
-			return 0, err
+		yylex.currPos += size
+	}
+	yylex.finPos = yylex.currPos
+}
+
+{{ end -}}
+// scanError returns the error of runes no rule matches when the lexer gets stuck.
+// It must be called before the runes are skipped.
+func (yylex *{{ .LexerName }}) scanError() *{{ .PublicPrefix }}ScanError {
+	e := &{{ .PublicPrefix }}ScanError{
+		Pos:       yylex.in.position,
+		Partial:   string(yylex.in.buf[yylex.beginPos:yylex.currPos]),
+		Rune:      -1,
+		Condition: yylex.cond,
+	}
+	if yylex.currPos < len(yylex.in.buf) {
+		e.Rune, _ = utf8.DecodeRune(yylex.in.buf[yylex.currPos:])
+	}
+
+	return e
+}
+
+// recoverError handles the scan error err after the runes no rule matches are skipped.
+// It returns nil if lexing continues.
+func (yylex *{{ .LexerName }}) recoverError(err error) error {
+	if yylex.OnError != nil {
+		if err = yylex.OnError(err); err == nil {
+			return nil
 		}
 	}
+{{- if .CollectErrors }}
+	yylex.Errors = append(yylex.Errors, err)
 
-	return 0, io.EOF
+	return nil
+{{- else }}
+
+	return err
+{{- end }}
+}
+{{- end }}
+
+// NextToken is the same as Next, but returns the token with its text and positions.
+// With Err{{ .UpperPrefix }}Scan the token has the text and positions of the rune no rule matched,
+// and with io.EOF it has the position of the end of the input.
+func (yylex *{{ .LexerName }}) NextToken() ({{ .PublicPrefix }}Token, error) {
+	kind, err := yylex.Next()
+	return {{ .PublicPrefix }}Token{
+		Kind:  kind,
+		Text:  yylex.YYText,
+		Start: yylex.start,
+		End:   yylex.end,
+	}, err
+}
+
+// Tokens lexes the rest of the input and returns the tokens.
+// It stops at the first error other than io.EOF and returns the tokens before it with the error.
+func (yylex *{{ .LexerName }}) Tokens() ([]{{ .PublicPrefix }}Token, error) {
+	toks := make([]{{ .PublicPrefix }}Token, 0)
+	for {
+		tok, err := yylex.NextToken()
+		if errors.Is(err, io.EOF) {
+			return toks, nil
+		} else if err != nil {
+			return toks, err
+		}
+		toks = append(toks, tok)
+	}
+}
+
+// Each calls yield with the rest of the tokens in order until yield returns false.
+// An error other than io.EOF is passed to yield and ends the iteration.
+// When yield stops the iteration, the lexer is just after the last token passed to yield,
+// so lexing can be resumed by Next, NextToken or Each.
+// It is the fallback of All for Go older than 1.23.
+func (yylex *{{ .LexerName }}) Each(yield func({{ .PublicPrefix }}Token, error) bool) {
+	for {
+		tok, err := yylex.NextToken()
+		if errors.Is(err, io.EOF) {
+			return
+		}
+		if !yield(tok, err) || err != nil {
+			return
+		}
+	}
+}
+
+// Less pushes back the current token except its first n bytes, and they are scanned again.
+// It corresponds to yyless of lex. n must be between 0 and len(Bytes()), and
+// it returns Err{{ .UpperPrefix }}NegativeCount or Err{{ .UpperPrefix }}LongCount without changing anything otherwise.
+// Less(0) without changing the start condition makes an infinite loop.
+func (yylex *{{ .LexerName }}) Less(n int) error {
+	if n < 0 {
+		return Err{{ .UpperPrefix }}NegativeCount
+	} else if n > len(yylex.text) {
+		return Err{{ .UpperPrefix }}LongCount
+	}
+	yylex.text = yylex.text[:n]
+	yylex.YYText = yylex.YYText[:n]
+{{- if .GlobalText }}
+	{{ .UpperPrefix }}Text = yylex.YYText
+{{- end }}
+	yylex.beginPos = yylex.textPos + n
+	yylex.finPos = yylex.beginPos
+	yylex.currPos = yylex.beginPos
+	yylex.in.position = yylex.start
+	yylex.in.cr = yylex.startCR
+{{- if .DisplayColumn }}
+	yylex.in.advance(yylex.text, yylex.TabWidth)
+{{- else }}
+	yylex.in.advance(yylex.text)
+{{- end }}
+	yylex.end = yylex.in.position
+{{- if .Yylineno }}
+	yylex.YYLineno = yylex.end.Line
+{{- end }}
+
+	return nil
 }
 
+// PeekRune returns the next rune of the input without consuming it.
+// It returns io.EOF at the end of the input.
+func (yylex *{{ .LexerName }}) PeekRune() (rune, error) {
+	r, _, err := yylex.currRune()
+	// reading may move the buffer
+	yylex.text = yylex.in.buf[yylex.textPos:yylex.beginPos]
+
+	return r, err
+}
+
+// ReadRune reads the next rune of the input and appends it to the current token.
+// It returns io.ErrUnexpectedEOF at the end of the input because the current token is unterminated,
+// so returning the error from the action does not end lexing silently.
+func (yylex *{{ .LexerName }}) ReadRune() (rune, int, error) {
+	r, size, err := yylex.currRune()
+	if err != nil {
+		yylex.text = yylex.in.buf[yylex.textPos:yylex.beginPos]
+		if errors.Is(err, io.EOF) {
+			err = io.ErrUnexpectedEOF
+		}
+		return 0, 0, err
+	}
+	yylex.extend(size)
+
+	return r, size, nil
+}
+
+// ReadUntil reads the input until the first occurrence of delim and appends it to the current token.
+// It returns the read text including delim.
+// If the input ends before delim, it reads the rest and returns io.ErrUnexpectedEOF.
+func (yylex *{{ .LexerName }}) ReadUntil(delim string) (string, error) {
+	d := {{ .Prefix }}StringBytes(delim)
+	searched := 0 // number of bytes from the read position which do not begin delim
+	for {
+		rest := yylex.in.buf[yylex.beginPos:]
+		if i := bytes.Index(rest[searched:], d); i >= 0 {
+			n := searched + i + len(d)
+			s := yylex.textString(rest[:n])
+			yylex.extend(n)
+			return s, nil
+		}
+		if len(rest) >= len(d) {
+			searched = len(rest) - len(d) + 1
+		}
+		if err := yylex.fill(); err != nil {
+			s := yylex.textString(yylex.in.buf[yylex.beginPos:])
+			yylex.extend(len(yylex.in.buf) - yylex.beginPos)
+			if errors.Is(err, io.EOF) {
+				err = io.ErrUnexpectedEOF
+			}
+			return s, err
+		}
+	}
+}
+
+// Consume reads n bytes of the input and appends them to the current token.
+// If the input ends before n bytes, it reads the rest and returns io.ErrUnexpectedEOF.
+// It returns the number of bytes read, and Err{{ .UpperPrefix }}NegativeCount if n is negative.
+func (yylex *{{ .LexerName }}) Consume(n int) (int, error) {
+	if n < 0 {
+		return 0, Err{{ .UpperPrefix }}NegativeCount
+	}
+	for len(yylex.in.buf)-yylex.beginPos < n {
+		if err := yylex.fill(); err != nil {
+			m := len(yylex.in.buf) - yylex.beginPos
+			yylex.extend(m)
+			if errors.Is(err, io.EOF) {
+				err = io.ErrUnexpectedEOF
+			}
+			return m, err
+		}
+	}
+	yylex.extend(n)
+
+	return n, nil
+}
+
+// extend appends n bytes at the read position to the current token.
+func (yylex *{{ .LexerName }}) extend(n int) {
+{{- if .DisplayColumn }}
+	yylex.in.advance(yylex.in.buf[yylex.beginPos:yylex.beginPos+n], yylex.TabWidth)
+{{- else }}
+	yylex.in.advance(yylex.in.buf[yylex.beginPos:yylex.beginPos+n])
+{{- end }}
+	yylex.beginPos += n
+	yylex.finPos = yylex.beginPos
+	yylex.currPos = yylex.beginPos
+	yylex.text = yylex.in.buf[yylex.textPos:yylex.beginPos]
+	yylex.YYText = yylex.textString(yylex.text)
+{{- if .GlobalText }}
+	{{ .UpperPrefix }}Text = yylex.YYText
+{{- end }}
+	yylex.end = yylex.in.position
+{{- if .Yylineno }}
+	yylex.YYLineno = yylex.end.Line
+{{- end }}
+}
+
+// More makes the next token appended to the current text instead of replacing it.
+// It corresponds to yymore of lex. Pos() of the next token is the beginning of the current one.
+// The current text is dropped if the next runes match no rule or the input is switched.
+func (yylex *{{ .LexerName }}) More() {
+	yylex.more = true
+}
+
+// Unput pushes s back to the input, and it is scanned before the rest of the input.
+// It corresponds to unput of lex. Positions count s as a part of the input.
+// The input is never modified; the current text, s and the buffered bytes are copied to a new buffer.
+// The rest of an in-memory input is read through a reader afterwards instead of being copied,
+// so texts of the following tokens do not share the memory with the input.
+func (yylex *{{ .LexerName }}) Unput(s string) {
+	in := &yylex.in
+	n := len(yylex.text)
+	rest := in.buf[yylex.beginPos:]
+	if in.r == nil {
+		in.r = bytes.NewReader(rest)
+		in.err = nil
+		rest = nil
+	}
+	buf := make([]byte, 0, n+len(s)+len(rest)+{{ .Prefix }}BufSize)
+	buf = append(buf, in.buf[yylex.textPos:yylex.beginPos]...)
+	buf = append(buf, s...)
+	buf = append(buf, rest...)
+	in.buf = buf
+	yylex.text = buf[:n]
+	yylex.textPos = 0
+	yylex.beginPos = n
+	yylex.finPos = n
+	yylex.currPos = n
+}
+
+// Begin switches the start condition. It corresponds to BEGIN of lex.
+func (yylex *{{ .LexerName }}) Begin(cond int) {
+	yylex.cond = cond
+	yylex.currStateID = {{ .Prefix }}StartStates[cond]
+}
+
+// StartCondition returns the current start condition.
+func (yylex *{{ .LexerName }}) StartCondition() int {
+	return yylex.cond
+}
+
+// PushState saves the current start condition on the stack and switches to cond.
+// It corresponds to yy_push_state of flex.
+func (yylex *{{ .LexerName }}) PushState(cond int) {
+	yylex.condStack = append(yylex.condStack, yylex.cond)
+	yylex.Begin(cond)
+}
+
+// PopState switches to the start condition on the top of the stack and removes it.
+// It returns Err{{ .UpperPrefix }}EmptyStack if the stack is empty.
+func (yylex *{{ .LexerName }}) PopState() error {
+	n := len(yylex.condStack)
+	if n == 0 {
+		return Err{{ .UpperPrefix }}EmptyStack
+	}
+	cond := yylex.condStack[n-1]
+	yylex.condStack = yylex.condStack[:n-1]
+	yylex.Begin(cond)
+
+	return nil
+}
+
+// TopState returns the start condition on the top of the stack.
+// It returns Err{{ .UpperPrefix }}EmptyStack if the stack is empty.
+func (yylex *{{ .LexerName }}) TopState() (int, error) {
+	n := len(yylex.condStack)
+	if n == 0 {
+		return 0, Err{{ .UpperPrefix }}EmptyStack
+	}
+
+	return yylex.condStack[n-1], nil
+}
+
+// StateStack returns a copy of the start condition stack. The last element is the top.
+func (yylex *{{ .LexerName }}) StateStack() []int {
+	return append([]int{}, yylex.condStack...)
+}
+
+// PushInput suspends the current input and starts reading r.
+// The suspended input is resumed when r is exhausted or PopInput is called.
+func (yylex *{{ .LexerName }}) PushInput(r {{ .InputType }}) {
+	in := yylex.in
+	in.pos = yylex.currPos
+	yylex.inputStack = append(yylex.inputStack, in)
+	yylex.in = {{ .Prefix }}Input{r: r, position: {{ .Prefix }}StartPosition}
+	yylex.textPos = 0
+	yylex.more = false
+	yylex.beginPos = 0
+	yylex.finPos = 0
+	yylex.currPos = 0
+	yylex.finRegexID = 0
+	yylex.currStateID = {{ .Prefix }}StartStates[yylex.cond]
+	yylex.inputID++
+}
+
+// PopInput discards the current input and resumes the last suspended one.
+// It returns false if there is no suspended input.
+func (yylex *{{ .LexerName }}) PopInput() bool {
+	n := len(yylex.inputStack)
+	if n == 0 {
+		return false
+	}
+	yylex.in = yylex.inputStack[n-1]
+	yylex.inputStack = yylex.inputStack[:n-1]
+	yylex.textPos = yylex.in.pos
+	yylex.more = false
+	yylex.beginPos = yylex.in.pos
+	yylex.finPos = yylex.in.pos
+	yylex.currPos = yylex.in.pos
+	yylex.finRegexID = 0
+	yylex.currStateID = {{ .Prefix }}StartStates[yylex.cond]
+	yylex.inputID++
+
+	return true
+}
+
+{{- if .DisplayColumn }}
+{{ template "width" . }}
+{{- end }}
+
 {{ .UserCodeTmpl }}
 `
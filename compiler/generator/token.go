@@ -0,0 +1,218 @@
+package generator
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"strings"
+)
+
+const tokenKindType = "TokenKind"
+
+// tokenKindName returns the name of the token kind type, which has the public prefix.
+func tokenKindName(cfg Config) string {
+	return cfg.PublicPrefix() + tokenKindType
+}
+
+// parseTokenLine parses `%token` line such as `%token Keyword Identifier`.
+func parseTokenLine(line string) ([]string, error) {
+	names := strings.Fields(strings.TrimPrefix(line, "%token"))
+	if len(names) == 0 {
+		return nil, fmt.Errorf("%w: empty %%token", ErrInvalidToken)
+	}
+	for _, name := range names {
+		if !token.IsIdentifier(name) {
+			return nil, fmt.Errorf("%w: %q", ErrInvalidToken, name)
+		}
+	}
+
+	return names, nil
+}
+
+func (spec *Spec) addTokens(pos Pos, names []string) error {
+	for _, name := range names {
+		if spec.hasToken(name) {
+			return fmt.Errorf("%w: %v", ErrDuplicateToken, name)
+		}
+		spec.Tokens = append(spec.Tokens, name)
+		spec.tokenPos = append(spec.tokenPos, pos)
+	}
+
+	return nil
+}
+
+func (spec *Spec) hasToken(name string) bool {
+	for _, t := range spec.Tokens {
+		if t == name {
+			return true
+		}
+	}
+
+	return false
+}
+
+// kindType returns the type of token kind returned by generated Next().
+func (spec *Spec) kindType() string {
+	if len(spec.Tokens) == 0 {
+		return "int"
+	}
+
+	return tokenKindName(spec.Config)
+}
+
+func genTokenKinds(cfg Config, tokens []string) string {
+	if len(tokens) == 0 {
+		return ""
+	}
+	kind := tokenKindName(cfg)
+	names := cfg.Prefix + tokenKindType + "Names"
+
+	var buf bytes.Buffer
+	buf.WriteString(fmt.Sprintf("type %v int\n\n", kind))
+	buf.WriteString("const (\n")
+	for i, name := range tokens {
+		if i == 0 {
+			buf.WriteString(fmt.Sprintf("%v %v = iota + 1\n", name, kind))
+		} else {
+			buf.WriteString(name + "\n")
+		}
+	}
+	buf.WriteString(")\n\n")
+
+	buf.WriteString(fmt.Sprintf("var %v = [...]string{\n", names))
+	buf.WriteString("0: \"\",\n")
+	for _, name := range tokens {
+		buf.WriteString(fmt.Sprintf("%v: %q,\n", name, name))
+	}
+	buf.WriteString("}\n\n")
+
+	buf.WriteString(fmt.Sprintf("func (k %v) String() string {\n", kind))
+	buf.WriteString(fmt.Sprintf("if 0 < k && int(k) < len(%v) {\n", names))
+	buf.WriteString(fmt.Sprintf("return %v[k]\n", names))
+	buf.WriteString("}\n")
+	buf.WriteString(fmt.Sprintf("return fmt.Sprintf(\"%v(%%d)\", int(k))\n", kind))
+	buf.WriteString("}\n\n")
+
+	buf.WriteString(fmt.Sprintf("// %vByName maps a token name to its kind.\n", kind))
+	buf.WriteString(fmt.Sprintf("var %vByName = map[string]%v{\n", kind, kind))
+	for _, name := range tokens {
+		buf.WriteString(fmt.Sprintf("%q: %v,\n", name, name))
+	}
+	buf.WriteString("}\n")
+
+	return buf.String()
+}
+
+// checkActionTokens reports `return X, ...` in actions whose X is neither a declared token
+// nor an identifier declared in the definitions or user code section.
+// Tokens of declarative rules must be declared.
+func checkActionTokens(spec *Spec) error {
+	if len(spec.Tokens) == 0 {
+		return nil
+	}
+
+	known := map[string]bool{"nil": true, "true": true, "false": true, "iota": true}
+	for _, name := range spec.Tokens {
+		known[name] = true
+	}
+	for _, src := range []string{spec.Definition, spec.UserCode} {
+		for _, name := range topLevelNames(src) {
+			known[name] = true
+		}
+	}
+
+	rules := append(spec.Rules[:len(spec.Rules):len(spec.Rules)], spec.EOFRules...)
+	for _, rule := range rules {
+		if rule.Token != "" && !spec.hasToken(rule.Token) {
+			return newSpecError(rule.Pos, fmt.Errorf("%w: %v", ErrUndeclaredToken, rule.Token))
+		}
+		for _, name := range returnedIdents(rule.Action) {
+			if !known[name] {
+				return newSpecError(rule.Pos, fmt.Errorf("%w: %v", ErrUndeclaredToken, name))
+			}
+		}
+	}
+
+	return nil
+}
+
+// topLevelNames returns names declared at top level of Go source without package clause.
+func topLevelNames(src string) []string {
+	fset := token.NewFileSet()
+	f, _ := parser.ParseFile(fset, "", "package p\n"+src, parser.SkipObjectResolution)
+	if f == nil {
+		return nil
+	}
+
+	names := make([]string, 0)
+	for _, decl := range f.Decls {
+		switch decl := decl.(type) {
+		case *ast.FuncDecl:
+			if decl.Recv == nil {
+				names = append(names, decl.Name.Name)
+			}
+		case *ast.GenDecl:
+			for _, spec := range decl.Specs {
+				switch spec := spec.(type) {
+				case *ast.ValueSpec:
+					for _, n := range spec.Names {
+						names = append(names, n.Name)
+					}
+				case *ast.TypeSpec:
+					names = append(names, spec.Name.Name)
+				}
+			}
+		}
+	}
+
+	return names
+}
+
+// returnedIdents returns identifiers used as the first value of return statements in the action.
+// Variables declared in the action are excluded.
+func returnedIdents(action string) []string {
+	fset := token.NewFileSet()
+	f, err := parser.ParseFile(fset, "", "package p\nfunc _() "+action, parser.SkipObjectResolution)
+	if err != nil {
+		return nil
+	}
+
+	locals := make(map[string]bool)
+	returned := make([]string, 0)
+	ast.Inspect(f, func(n ast.Node) bool {
+		switch n := n.(type) {
+		case *ast.FuncLit:
+			return false
+		case *ast.AssignStmt:
+			if n.Tok == token.DEFINE {
+				for _, lhs := range n.Lhs {
+					if id, ok := lhs.(*ast.Ident); ok {
+						locals[id.Name] = true
+					}
+				}
+			}
+		case *ast.ValueSpec:
+			for _, id := range n.Names {
+				locals[id.Name] = true
+			}
+		case *ast.ReturnStmt:
+			if len(n.Results) > 0 {
+				if id, ok := n.Results[0].(*ast.Ident); ok {
+					returned = append(returned, id.Name)
+				}
+			}
+		}
+		return true
+	})
+
+	names := make([]string, 0, len(returned))
+	for _, name := range returned {
+		if !locals[name] {
+			names = append(names, name)
+		}
+	}
+
+	return names
+}
@@ -0,0 +1,227 @@
+package generator
+
+import (
+	"errors"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/scanner"
+	"go/token"
+	"sort"
+	"unicode"
+
+	"github.com/goropikari/tlex/automata"
+)
+
+type Severity int
+
+const (
+	SeverityWarning Severity = iota
+	SeverityError
+)
+
+func (s Severity) String() string {
+	if s == SeverityError {
+		return "error"
+	}
+
+	return "warning"
+}
+
+// Diagnostic is a finding of Vet.
+type Diagnostic struct {
+	Pos      Pos
+	Severity Severity
+	Message  string
+}
+
+func (d Diagnostic) String() string {
+	if d.Pos.Line == 0 {
+		if d.Pos.File == "" {
+			return fmt.Sprintf("%v: %v", d.Severity, d.Message)
+		}
+		return fmt.Sprintf("%v: %v: %v", d.Pos.File, d.Severity, d.Message)
+	}
+
+	return fmt.Sprintf("%v: %v: %v", d.Pos, d.Severity, d.Message)
+}
+
+// Vet checks the lexer configuration for common mistakes.
+//   - errors: undeclared tokens and rules which can never be matched
+//   - warnings: rules matching the empty string, inputs which no rule matches,
+//     actions which return on some paths only and tokens which are never used
+//
+// Diagnostics are sorted by position.
+func Vet(spec *Spec) []Diagnostic {
+	diags := make([]Diagnostic, 0)
+	if err := checkActionTokens(spec); err != nil {
+		var serr *SpecError
+		if errors.As(err, &serr) {
+			diags = append(diags, Diagnostic{Pos: serr.Pos, Severity: SeverityError, Message: serr.Err.Error()})
+		} else {
+			diags = append(diags, Diagnostic{Pos: Pos{File: spec.filename}, Severity: SeverityError, Message: err.Error()})
+		}
+	}
+	for _, sr := range FindShadowedRules(spec) {
+		if sr.Never {
+			diags = append(diags, Diagnostic{Pos: sr.Rule.Pos, Severity: SeverityError, Message: fmt.Sprintf("rule %v can never be matched", sr.Rule.Regex)})
+		}
+	}
+	diags = append(diags, vetEmptyMatches(spec)...)
+	diags = append(diags, vetCatchAll(spec)...)
+	diags = append(diags, vetReturns(spec)...)
+	diags = append(diags, vetUnusedTokens(spec)...)
+
+	sort.SliceStable(diags, func(i, j int) bool {
+		if diags[i].Pos.File != diags[j].Pos.File {
+			return diags[i].Pos.File < diags[j].Pos.File
+		}
+		return diags[i].Pos.Line < diags[j].Pos.Line
+	})
+
+	return diags
+}
+
+// vetEmptyMatches reports rules matching the empty string. The lexer never accepts empty tokens.
+func vetEmptyMatches(spec *Spec) []Diagnostic {
+	diags := make([]Diagnostic, 0)
+	for _, rule := range spec.Rules {
+		dfa := parse(rule.Regex, spec.Config.Caseless).ToImdNFA().ToDFA()
+		if dfa.GetFinStates().Contains(dfa.GetInitState()) {
+			diags = append(diags, Diagnostic{
+				Pos:      rule.Pos,
+				Severity: SeverityWarning,
+				Message:  fmt.Sprintf("rule %v matches the empty string, which is never accepted", rule.Regex),
+			})
+		}
+	}
+
+	return diags
+}
+
+// vetCatchAll reports start conditions where some rune isn't matched by any rule,
+// in which case Next returns ErrYYScan. Lexers which echo or collect such runes are not reported.
+func vetCatchAll(spec *Spec) []Diagnostic {
+	if len(spec.Rules) == 0 || spec.Config.Recover == RecoverEcho || spec.Config.CollectErrors {
+		return nil
+	}
+	regexs := make([]string, 0, len(spec.Rules))
+	for _, rule := range spec.Rules {
+		regexs = append(regexs, rule.Regex)
+	}
+	dfa := lexerDFA(regexs, spec.activeRules(), spec.Config.Caseless)
+	trans := dfa.GetTransitionTable()
+
+	diags := make([]Diagnostic, 0)
+	for c, init := range dfa.GetInitStates() {
+		// a rune is scanned without error iff it is a token by itself.
+		next := 0
+		for _, intv := range trans.Intervals(init) {
+			to, _ := trans.Get(init, intv)
+			if !dfa.GetFinStates().Contains(to) || intv.L > next {
+				break
+			}
+			next = intv.R + 1
+		}
+		if next > unicode.MaxRune {
+			continue
+		}
+		diags = append(diags, Diagnostic{
+			Pos:      spec.conditionPosOf(c),
+			Severity: SeverityWarning,
+			Message: fmt.Sprintf("no rule matches %q in %v, so Next returns ErrYYScan; add a catch-all rule such as `.`",
+				string(automata.NewInterval(next, next).Sample()), spec.conditions()[c].Name),
+		})
+	}
+
+	return diags
+}
+
+// vetReturns reports actions which return a token on some paths but continue scanning on others.
+func vetReturns(spec *Spec) []Diagnostic {
+	diags := make([]Diagnostic, 0)
+	for _, rule := range spec.Rules {
+		if rule.IsDeclarative() || !hasReturn(rule.Action) || isTerminating(rule.Action) {
+			continue
+		}
+		diags = append(diags, Diagnostic{
+			Pos:      rule.actionPos(),
+			Severity: SeverityWarning,
+			Message:  fmt.Sprintf("action of rule %v may not return; scanning continues when it doesn't", rule.Regex),
+		})
+	}
+
+	return diags
+}
+
+// hasReturn reports whether the action has return statements out of function literals.
+func hasReturn(action string) bool {
+	fset := token.NewFileSet()
+	f, err := parser.ParseFile(fset, "", "package p\nfunc _() "+action, parser.SkipObjectResolution)
+	if err != nil {
+		return false
+	}
+
+	found := false
+	ast.Inspect(f, func(n ast.Node) bool {
+		switch n.(type) {
+		case *ast.FuncLit:
+			return false
+		case *ast.ReturnStmt:
+			found = true
+		}
+		return !found
+	})
+
+	return found
+}
+
+// vetUnusedTokens reports declared tokens which appear neither in rules nor in code sections.
+func vetUnusedTokens(spec *Spec) []Diagnostic {
+	used := make(map[string]bool)
+	srcs := []string{spec.Definition, spec.UserCode}
+	for _, rule := range append(spec.Rules[:len(spec.Rules):len(spec.Rules)], spec.EOFRules...) {
+		used[rule.Token] = true
+		srcs = append(srcs, rule.Action)
+	}
+	for _, src := range srcs {
+		for _, name := range identifiers(src) {
+			used[name] = true
+		}
+	}
+
+	diags := make([]Diagnostic, 0)
+	for i, name := range spec.Tokens {
+		if used[name] {
+			continue
+		}
+		diags = append(diags, Diagnostic{
+			Pos:      spec.tokenPos[i],
+			Severity: SeverityWarning,
+			Message:  fmt.Sprintf("token %v is never used", name),
+		})
+	}
+
+	return diags
+}
+
+// identifiers returns all identifiers in Go source.
+func identifiers(src string) []string {
+	fset := token.NewFileSet()
+	file := fset.AddFile("", fset.Base(), len(src))
+	var s scanner.Scanner
+	s.Init(file, []byte(src), nil, 0)
+
+	names := make([]string, 0)
+	for {
+		_, tok, lit := s.Scan()
+		if tok == token.EOF {
+			break
+		}
+		if tok == token.IDENT {
+			names = append(names, lit)
+		}
+	}
+
+	return names
+}
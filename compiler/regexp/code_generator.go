@@ -1,6 +1,7 @@
 package regexp
 
 import (
+	"sort"
 	"unicode"
 
 	"github.com/goropikari/tlex/automata"
@@ -8,13 +9,20 @@ import (
 )
 
 type CodeGenerator struct {
-	nfa *automata.NFA
+	nfa      *automata.NFA
+	caseless bool
 }
 
 func NewCodeGenerator() *CodeGenerator {
 	return &CodeGenerator{}
 }
 
+// SetCaseless makes generated NFA match letters regardless of their case.
+func (gen *CodeGenerator) SetCaseless(caseless bool) *CodeGenerator {
+	gen.caseless = caseless
+	return gen
+}
+
 func (gen *CodeGenerator) GetNFA() *automata.NFA {
 	return gen.nfa
 }
@@ -45,10 +53,14 @@ func (gen *CodeGenerator) VisitStarExpr(expr StarExpr) {
 func (gen *CodeGenerator) VisitSymbolExpr(expr SymbolExpr) {
 	from := automata.NewStateID()
 	to := automata.NewStateID()
+	trans := automata.NewNFATransition()
+	for _, intv := range gen.fold([]automata.Interval{automata.NewInterval(int(expr.sym), int(expr.sym))}) {
+		trans.Set(from, intv, to)
+	}
 	gen.nfa = automata.NewNFA(
 		collection.NewSet[automata.StateID]().Insert(from).Insert(to),
 		automata.NewEpsilonTransition(),
-		automata.NewNFATransition().Set(from, automata.NewInterval(int(expr.sym), int(expr.sym)), to),
+		trans,
 		collection.NewSet[automata.StateID]().Insert(from),
 		collection.NewSet[automata.StateID]().Insert(to),
 	)
@@ -59,7 +71,11 @@ func (gen *CodeGenerator) VisitRangeExpr(expr RangeExpr) {
 	to := automata.NewStateID()
 	trans := automata.NewNFATransition()
 
-	intvs := expr.intervals()
+	// case variants are added before the negation, so that caseless [^a-z] matches neither a nor A.
+	intvs := gen.fold(expr.intervals())
+	if expr.neg {
+		intvs = complement(intvs)
+	}
 	for _, intv := range intvs {
 		trans.Set(from, intv, to)
 	}
@@ -100,3 +116,53 @@ func (gen *CodeGenerator) VisitDotExpr(expr DotExpr) {
 		finStates,
 	)
 }
+
+// fold adds case variants of runes in intvs when the generator is caseless.
+func (gen *CodeGenerator) fold(intvs []automata.Interval) []automata.Interval {
+	if !gen.caseless {
+		return intvs
+	}
+
+	ret := append([]automata.Interval{}, intvs...)
+	for _, intv := range intvs {
+		for r := intv.L; r <= intv.R; r++ {
+			for f := unicode.SimpleFold(rune(r)); f != rune(r); f = unicode.SimpleFold(f) {
+				if !contains(intvs, int(f)) {
+					ret = append(ret, automata.NewInterval(int(f), int(f)))
+				}
+			}
+		}
+	}
+
+	return merge(ret)
+}
+
+func contains(intvs []automata.Interval, r int) bool {
+	for _, intv := range intvs {
+		if intv.L <= r && r <= intv.R {
+			return true
+		}
+	}
+	return false
+}
+
+// merge unites overlapping or adjacent intervals.
+func merge(intvs []automata.Interval) []automata.Interval {
+	sort.Slice(intvs, func(i, j int) bool {
+		return intvs[i].L < intvs[j].L
+	})
+
+	ret := make([]automata.Interval, 0, len(intvs))
+	for _, intv := range intvs {
+		n := len(ret)
+		if n > 0 && intv.L <= ret[n-1].R+1 {
+			if intv.R > ret[n-1].R {
+				ret[n-1].R = intv.R
+			}
+			continue
+		}
+		ret = append(ret, intv)
+	}
+
+	return ret
+}
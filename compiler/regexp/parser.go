@@ -315,26 +315,29 @@ func (expr RangeExpr) Accept(v NodeVisitor) {
 	v.VisitRangeExpr(expr)
 }
 
+// intervals returns the runes in the brackets, before the negation of `[^...]` is applied.
 func (expr RangeExpr) intervals() []automata.Interval {
-	tmpIntvs := make([]automata.Interval, 0)
+	intvs := make([]automata.Interval, 0)
 	for _, intv := range expr.intvs {
-		tmpIntvs = append(tmpIntvs, automata.NewInterval(intv.l, intv.r))
-	}
-	if !expr.neg {
-		return tmpIntvs
+		intvs = append(intvs, automata.NewInterval(intv.l, intv.r))
 	}
 
+	return intvs
+}
+
+// complement returns the runes which are not in intvs.
+func complement(intvs []automata.Interval) []automata.Interval {
 	deq := collection.NewDeque[automata.Interval]()
 	for _, intv := range automata.UnicodeRange {
 		deq.PushBack(intv)
 	}
 
-	intvs := make([]automata.Interval, 0)
+	ret := make([]automata.Interval, 0)
 	for deq.Size() > 0 {
 		fr := deq.Front()
 		deq.PopFront()
 		ok := true
-		for _, intv := range tmpIntvs {
+		for _, intv := range intvs {
 			if fr.Overlap(intv) {
 				ok = false
 				ls := fr.Difference(intv)
@@ -345,11 +348,11 @@ func (expr RangeExpr) intervals() []automata.Interval {
 			}
 		}
 		if ok {
-			intvs = append(intvs, fr)
+			ret = append(ret, fr)
 		}
 	}
 
-	return intvs
+	return ret
 }
 
 type DotExpr struct {
@@ -1,37 +1,114 @@
 package main
 
 import (
-	"bufio"
+	"encoding/json"
 	"flag"
 	"fmt"
 	"log"
 	"os"
+	"strings"
 
 	"github.com/goropikari/tlex/compiler/generator"
 )
 
 var (
 	pkgName string
+	backend string
 	srcfile string
 	outfile string
+	options optionFlags
+	shadow  bool
+	overlap bool
+	jsonOut bool
 )
 
+// optionFlags is a repeatable `-option name=value` flag.
+type optionFlags []generator.Option
+
+func (opts *optionFlags) String() string {
+	strs := make([]string, 0, len(*opts))
+	for _, opt := range *opts {
+		strs = append(strs, opt.Name+"="+opt.Value)
+	}
+	return strings.Join(strs, ",")
+}
+
+func (opts *optionFlags) Set(s string) error {
+	opt, err := generator.ParseOption(s)
+	if err != nil {
+		return err
+	}
+	*opts = append(*opts, opt)
+	return nil
+}
+
 func main() {
 	// go get github.com/pkg/profile
 	// go tool pprof -http=":8081" cpu.pprof
 	// defer profile.Start(profile.ProfilePath(".")).Stop()
+	if len(os.Args) > 1 && os.Args[1] == "vet" {
+		os.Exit(runVet(os.Args[2:]))
+	}
+
 	flag.StringVar(&pkgName, "pkg", "main", "generated go file package name")
 	flag.StringVar(&srcfile, "src", "", "input lexer configuration file")
 	flag.StringVar(&outfile, "o", "tlex.yy.go", "generated file path")
+	flag.StringVar(&backend, "backend", generator.BackendTable, "lexer backend. table: table driven, goto: direct coded states jumping by goto")
+	flag.Var(&options, "option", "override %option of the configuration file. e.g. -option caseless -option prefix=foo")
+	flag.BoolVar(&shadow, "shadow", false, "report partially shadowed rules with example strings as well as rules which can never be matched")
+	flag.BoolVar(&overlap, "overlap", false, "report every pair of rules matching a common string")
+	flag.BoolVar(&jsonOut, "json", false, "print the -overlap report in JSON")
 	flag.Parse()
 	if srcfile == "" {
 		fmt.Fprint(os.Stderr, "srcfile is required.\n")
 	}
 
-	f, err := os.OpenFile(srcfile, os.O_RDONLY, 0644)
+	// -pkg and -backend override %option only when they are given explicitly.
+	flag.Visit(func(f *flag.Flag) {
+		switch f.Name {
+		case "pkg":
+			options = append(options, generator.Option{Name: "package", Value: pkgName})
+		case "backend":
+			options = append(options, generator.Option{Name: "backend", Value: backend})
+		}
+	})
+
+	spec, err := generator.ParseFile(srcfile, options...)
 	if err != nil {
 		log.Fatal(err)
 	}
-	r := bufio.NewReader(f)
-	generator.Generate(r, pkgName, outfile)
+	if err := generator.GenerateSpec(spec, outfile); err != nil {
+		log.Fatal(err)
+	}
+	reportShadowedRules(spec)
+	if overlap {
+		if err := reportOverlaps(spec); err != nil {
+			log.Fatal(err)
+		}
+	}
+}
+
+// reportShadowedRules warns rules which can never be matched like flex.
+// Partially shadowed rules are reported with -shadow.
+func reportShadowedRules(spec *generator.Spec) {
+	for _, sr := range generator.FindShadowedRules(spec) {
+		if sr.Never || shadow {
+			fmt.Fprintf(os.Stderr, "warning: %v\n", sr)
+		}
+	}
+}
+
+// reportOverlaps prints pairs of rules matching a common string to stdout.
+func reportOverlaps(spec *generator.Spec) error {
+	overlaps := generator.FindOverlaps(spec)
+	if jsonOut {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(overlaps)
+	}
+	for _, o := range overlaps {
+		fmt.Println(o)
+	}
+
+	return nil
 }
@@ -0,0 +1,943 @@
+// Code generated by tlex. DO NOT EDIT.
+
+package action
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+	"unsafe"
+)
+
+//line action.l:7
+var ErrUnterminatedComment = errors.New("unterminated comment")
+
+//line action.l:8
+
+// macros are expanded by Unput.
+var macros = map[string]string{
+	"x":  "foo 12",
+	"gt": ">>",
+}
+
+//line action.go:28
+
+type TokenKind int
+
+const (
+	Ident TokenKind = iota + 1
+	Number
+	Greater
+	Heredoc
+	CodeSpan
+	Comment
+	Blob
+)
+
+var yyTokenKindNames = [...]string{
+	0:        "",
+	Ident:    "Ident",
+	Number:   "Number",
+	Greater:  "Greater",
+	Heredoc:  "Heredoc",
+	CodeSpan: "CodeSpan",
+	Comment:  "Comment",
+	Blob:     "Blob",
+}
+
+func (k TokenKind) String() string {
+	if 0 < k && int(k) < len(yyTokenKindNames) {
+		return yyTokenKindNames[k]
+	}
+	return fmt.Sprintf("TokenKind(%d)", int(k))
+}
+
+// TokenKindByName maps a token name to its kind.
+var TokenKindByName = map[string]TokenKind{
+	"Ident":    Ident,
+	"Number":   Number,
+	"Greater":  Greater,
+	"Heredoc":  Heredoc,
+	"CodeSpan": CodeSpan,
+	"Comment":  Comment,
+	"Blob":     Blob,
+}
+
+// names of start conditions
+var yyConditionNames = [...]string{
+	"INITIAL",
+	"HEREDOC",
+}
+
+// start conditions
+const (
+	INITIAL = iota
+	HEREDOC
+)
+
+type yyStateID = int
+type yyRegexID = int
+
+var (
+	ErrYYScan          = errors.New("failed to scan")
+	ErrYYEmptyStack    = errors.New("start condition stack is empty")
+	ErrYYNegativeCount = errors.New("negative count")
+	ErrYYLongCount     = errors.New("count exceeds the current token")
+)
+
+// start state of each start condition
+var yyStartStates = []yyStateID{
+	1,
+	2,
+}
+
+// state id to regex id
+var yyStateIDToRegexID = []yyRegexID{
+	0, // state 0 is dead state
+	9223372036854775807,
+	9223372036854775807,
+	11,
+	9223372036854775807,
+	9223372036854775807,
+	2,
+	9223372036854775807,
+	3,
+	8,
+	1,
+	7,
+	5,
+	9,
+	10,
+	9223372036854775807,
+	4,
+	9223372036854775807,
+	6,
+}
+
+var yyFinStates = []bool{
+	false,
+	false,
+	false,
+	true,
+	false,
+	false,
+	true,
+	false,
+	true,
+	true,
+	true,
+	true,
+	true,
+	true,
+	true,
+	false,
+	true,
+	false,
+	true,
+}
+
+// class of each rune less than 256. class 0 has no transition.
+var yyByteClasses = [256]int32{
+	1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 2, 1, 1, 1, 1, 1,
+	1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1,
+	3, 1, 1, 1, 4, 1, 1, 1, 1, 1, 5, 1, 1, 1, 1, 6,
+	7, 7, 7, 7, 7, 7, 7, 7, 7, 7, 8, 1, 9, 1, 10, 1,
+	1, 11, 11, 11, 11, 11, 11, 11, 11, 11, 11, 11, 11, 11, 11, 11,
+	11, 11, 11, 11, 11, 11, 11, 11, 11, 11, 11, 1, 1, 1, 1, 1,
+	12, 13, 13, 13, 13, 13, 13, 13, 13, 13, 13, 13, 13, 13, 13, 13,
+	13, 13, 13, 13, 13, 13, 13, 13, 13, 13, 13, 1, 1, 1, 1, 1,
+	1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1,
+	1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1,
+	1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1,
+	1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1,
+	1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1,
+	1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1,
+	1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1,
+	1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1,
+}
+
+// yyclassRange is the class of runes in [l, r].
+type yyclassRange struct {
+	l, r  int32
+	class int32
+}
+
+// classes of runes not less than 256 in ascending order of runes
+var yyClassRanges = []yyclassRange{
+	{256, 1114111, 1},
+}
+
+const yyNumClasses = 14
+
+// yyTransitions[s*yyNumClasses+c] is the next state of state s by a rune of class c.
+var yyTransitions = []yyStateID{
+	0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, // state 0 is dead state
+	0, 0, 3, 3, 4, 0, 5, 6, 0, 7, 8, 0, 9, 10,
+	0, 2, 11, 2, 2, 2, 2, 2, 2, 2, 2, 2, 2, 2,
+	0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0,
+	0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 12,
+	0, 0, 0, 0, 0, 13, 0, 0, 0, 0, 0, 0, 0, 0,
+	0, 0, 0, 0, 0, 0, 0, 6, 14, 0, 0, 0, 0, 0,
+	0, 0, 0, 0, 0, 0, 0, 0, 0, 15, 0, 0, 0, 0,
+	0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 16, 0, 0, 0,
+	0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 9, 0,
+	0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 10,
+	0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0,
+	0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 12,
+	0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0,
+	0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0,
+	0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 17, 0, 0,
+	0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0,
+	0, 0, 18, 0, 0, 0, 0, 0, 0, 0, 0, 17, 0, 0,
+	0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0,
+}
+
+func yyClassOf(r rune) int32 {
+	if 0 <= r && r < 256 {
+		return yyByteClasses[r]
+	}
+	i, j := 0, len(yyClassRanges)
+	for i < j {
+		h := int(uint(i+j) >> 1)
+		if yyClassRanges[h].r < r {
+			i = h + 1
+		} else {
+			j = h
+		}
+	}
+	if i < len(yyClassRanges) && yyClassRanges[i].l <= r {
+		return yyClassRanges[i].class
+	}
+
+	return 0
+}
+
+func yyNextStep(id yyStateID, r rune) yyStateID {
+	return yyTransitions[id*yyNumClasses+int(yyClassOf(r))]
+}
+
+func yyIsFinState(id yyStateID) bool {
+	return yyFinStates[id]
+}
+
+type yyLexer struct {
+	in          yyInput
+	beginPos    int // position of the current token in in.buf
+	finPos      int // end position of the longest match in in.buf
+	currPos     int // read position in in.buf
+	finRegexID  int
+	currStateID yyStateID
+	cond        int
+	condStack   []int
+	inputStack  []yyInput
+	inputID     int
+	textPos     int  // position of the text of the current token in in.buf
+	more        bool // the next token is appended to the current text
+	text        []byte
+	start       Position // position of the current token
+	startCR     bool     // in.cr at start
+	end         Position // position just after the current token
+	YYText      string
+	// OnError is called with a scan error after the runes no rule matches are skipped.
+	// Lexing continues if it returns nil, and the returned error is reported otherwise.
+	OnError func(err error) error
+}
+
+// yyBufSize is the initial size of the input buffer.
+// The buffer grows when a token does not fit in it.
+const yyBufSize = 4096
+
+// Position is a position in the input.
+// A line ends with LF, CR or CRLF, and CRLF is one line break.
+type Position struct {
+	Offset     int // byte offset, starting at 0
+	Line       int // line number, starting at 1
+	Column     int // column in runes, starting at 1
+	ByteColumn int // column in bytes, starting at 1
+}
+
+// yyStartPosition is the position of the beginning of an input.
+var yyStartPosition = Position{Line: 1, Column: 1, ByteColumn: 1}
+
+func (p Position) String() string {
+	return fmt.Sprintf("%d:%d", p.Line, p.Column)
+}
+
+// Token is a token returned by NextToken.
+// Unlike YYText, Text is not overwritten by later tokens.
+// With NewFromBytes, Text shares the memory with the input as YYText.
+type Token struct {
+	Kind  TokenKind
+	Text  string
+	Start Position // position of the beginning of the token
+	End   Position // position just after the token
+}
+
+// ScanError is the error of runes no rule matches.
+// It wraps ErrYYScan.
+type ScanError struct {
+	Pos       Position // position of the skipped runes
+	Text      string   // skipped runes
+	Partial   string   // text read from Pos before the lexer got stuck, which no rule matches
+	Rune      rune     // rune no rule can continue with after Partial, or -1 at the end of the input
+	Condition int      // start condition
+}
+
+func (e *ScanError) Error() string {
+	msg := fmt.Sprintf("%v: %v %q", e.Pos, ErrYYScan, e.Text)
+	if e.Condition != 0 {
+		msg += " in start condition " + yyConditionNames[e.Condition]
+	}
+
+	return msg
+}
+
+func (e *ScanError) Unwrap() error {
+	return ErrYYScan
+}
+
+// yyInput is an input and the buffer of bytes read from it.
+// Bytes before the current token are discarded when the buffer is refilled,
+// so the buffer size is bounded by the longest token.
+// An in-memory input has no reader and its buffer is the whole input, which is never modified.
+type yyInput struct {
+	r   io.Reader
+	buf []byte
+	err error // error of the last read, which is reported after buf is consumed
+	pos int   // read position of a suspended input
+
+	position Position // position of the beginning of the current token
+	cr       bool     // the last byte before position is CR
+}
+
+// advance moves the position over b.
+func (in *yyInput) advance(b []byte) {
+	p := &in.position
+	p.Offset += len(b)
+	for _, c := range b {
+		switch {
+		case c == '\n' && in.cr:
+			// LF of CRLF. the line break is counted at CR.
+		case c == '\n' || c == '\r':
+			p.Line++
+			p.Column = 1
+			p.ByteColumn = 1
+		default:
+			p.ByteColumn++
+			if utf8.RuneStart(c) {
+				p.Column++
+			}
+		}
+		in.cr = c == '\r'
+	}
+}
+
+func New(r io.Reader) *yyLexer {
+	return yyNewLexer(yyInput{r: r})
+}
+
+// NewFromBytes returns a lexer scanning b directly.
+// Bytes(), Text(), YYText and Text of tokens share the memory with b, and these strings outlive lexing.
+// b must not be modified while the lexer or any text returned by it is in use.
+func NewFromBytes(b []byte) *yyLexer {
+	return yyNewLexer(yyInput{buf: b, err: io.EOF})
+}
+
+// NewFromString returns a lexer scanning s without copying it.
+func NewFromString(s string) *yyLexer {
+	return yyNewLexer(yyInput{buf: yyStringBytes(s), err: io.EOF})
+}
+
+func yyNewLexer(in yyInput) *yyLexer {
+	in.position = yyStartPosition
+	return &yyLexer{
+		in:          in,
+		beginPos:    0,
+		finPos:      0,
+		currPos:     0,
+		finRegexID:  0,
+		currStateID: yyStartStates[0],
+		start:       in.position,
+		end:         in.position,
+		cond:        0,
+	}
+}
+
+// yyStringBytes returns the bytes of s without copying. They must not be modified.
+func yyStringBytes(s string) []byte {
+	return *(*[]byte)(unsafe.Pointer(&struct {
+		string
+		int
+	}{s, len(s)}))
+}
+
+// Bytes returns the text of the current token.
+// It is a sub-slice of the input buffer and valid until the next call of Next.
+// PeekRune, ReadRune, ReadUntil and Consume may refill the buffer and invalidate it as well.
+func (yylex *yyLexer) Bytes() []byte {
+	return yylex.text
+}
+
+// Pos returns the position of the beginning of the current token.
+func (yylex *yyLexer) Pos() Position {
+	return yylex.start
+}
+
+// EndPos returns the position just after the current token.
+func (yylex *yyLexer) EndPos() Position {
+	return yylex.end
+}
+
+// Text returns the text of the current token. It is the same as YYText.
+func (yylex *yyLexer) Text() string {
+	return yylex.YYText
+}
+
+// textString returns b as a string.
+// It shares the memory with an in-memory input, which the caller keeps unchanged while the text is in use.
+func (yylex *yyLexer) textString(b []byte) string {
+	if yylex.in.r == nil {
+		return *(*string)(unsafe.Pointer(&b))
+	}
+
+	return string(b)
+}
+
+// fill reads more bytes from the input after discarding bytes before the current token.
+func (yylex *yyLexer) fill() error {
+	in := &yylex.in
+	if in.err != nil {
+		return in.err
+	}
+	if keep := yylex.textPos; keep > 0 {
+		n := copy(in.buf, in.buf[keep:])
+		in.buf = in.buf[:n]
+		yylex.textPos = 0
+		yylex.beginPos -= keep
+		yylex.finPos -= keep
+		yylex.currPos -= keep
+	}
+	if len(in.buf) == cap(in.buf) {
+		buf := make([]byte, len(in.buf), 2*cap(in.buf)+yyBufSize)
+		copy(buf, in.buf)
+		in.buf = buf
+	}
+	for i := 0; i < 100; i++ {
+		n, err := in.r.Read(in.buf[len(in.buf):cap(in.buf)])
+		in.buf = in.buf[:len(in.buf)+n]
+		if err != nil {
+			in.err = err
+		}
+		if n > 0 {
+			return nil
+		} else if err != nil {
+			return err
+		}
+	}
+	in.err = io.ErrNoProgress
+
+	return in.err
+}
+
+// currRune returns the rune at the read position without consuming it.
+// An incomplete UTF-8 sequence at the end of the input is utf8.RuneError of size 1.
+func (yylex *yyLexer) currRune() (rune, int, error) {
+	for !utf8.FullRune(yylex.in.buf[yylex.currPos:]) {
+		if err := yylex.fill(); err != nil {
+			if yylex.currPos < len(yylex.in.buf) {
+				break
+			}
+			return 0, 0, err
+		}
+	}
+	ru, size := utf8.DecodeRune(yylex.in.buf[yylex.currPos:])
+
+	return ru, size, nil
+}
+
+// scan runs the DFA from the current state while transitions exist.
+// It returns false if the input is exhausted at the beginning of a token.
+func (yylex *yyLexer) scan() (bool, error) {
+	for {
+		yyr, yysize, err := yylex.currRune()
+		if err != nil {
+			return yylex.scanEnd(err)
+		}
+		yyNxStID := yyNextStep(yylex.currStateID, yyr)
+		if yyNxStID == 0 {
+			return true, nil
+		}
+		yylex.currStateID = yyNxStID
+		yylex.currPos += yysize
+		if yyIsFinState(yyNxStID) {
+			yylex.finPos = yylex.currPos
+			yylex.finRegexID = yyStateIDToRegexID[yyNxStID]
+		}
+	}
+}
+
+// scanEnd returns the result of scan when reading a rune fails by err.
+func (yylex *yyLexer) scanEnd(err error) (bool, error) {
+	if !errors.Is(err, io.EOF) {
+		return false, err
+	}
+
+	return yylex.currPos != yylex.beginPos, nil
+}
+
+func (yylex *yyLexer) Next() (TokenKind, error) {
+yystart:
+	for {
+		if !yylex.more {
+			yylex.textPos = yylex.beginPos
+		}
+		yymatched, err := yylex.scan()
+		if err != nil {
+			return 0, err
+		} else if !yymatched {
+			// input is exhausted
+			break
+		}
+		var yyerr *ScanError
+		if yylex.finRegexID == 0 {
+			// no rule matched, and the first rune is consumed. the text appended by More is dropped.
+			yylex.more = false
+			yyerr = yylex.scanError()
+			_, yysize := utf8.DecodeRune(yylex.in.buf[yylex.beginPos:])
+			yylex.finPos = yylex.beginPos + yysize
+		}
+		if !yylex.more {
+			yylex.textPos = yylex.beginPos
+			yylex.start = yylex.in.position
+			yylex.startCR = yylex.in.cr
+		}
+		yylex.more = false
+		yylex.text = yylex.in.buf[yylex.textPos:yylex.finPos]
+		yylex.YYText = yylex.textString(yylex.text)
+		yylex.in.advance(yylex.in.buf[yylex.beginPos:yylex.finPos])
+		yylex.end = yylex.in.position
+		yylex.beginPos = yylex.finPos
+		yylex.currPos = yylex.finPos
+		yylex.currStateID = yyStartStates[yylex.cond]
+
+		regexID := yylex.finRegexID
+		yylex.finRegexID = 0
+		switch regexID {
+		case 0:
+			yyerr.Text = yylex.YYText
+			if err := yylex.recoverError(yyerr); err != nil {
+				return 0, err
+			}
+		case 1:
+//line action.l:18
+			return Ident, nil
+//line action.go:539
+		case 2:
+//line action.l:19
+			return Number, nil
+//line action.go:543
+		case 3:
+//line action.l:20
+			return Greater, nil
+//line action.go:547
+		case 4:
+//line action.l:21
+			{
+				// split into two > for nested generics such as a<b<c>>
+				yylex.Less(1)
+				return Greater, nil
+			}
+//line action.go:555
+		case 5:
+//line action.l:26
+			{
+				yylex.Unput(macros[yylex.YYText[1:]])
+			}
+//line action.go:561
+			goto yystart
+		case 6:
+//line action.l:27
+			{
+				// the heredoc token includes the first line, which tells the terminator line
+				yylex.Begin(HEREDOC)
+				yylex.More()
+			}
+//line action.go:570
+			goto yystart
+		case 7:
+//line action.l:32
+			{
+				terminator := yylex.YYText[2 : strings.IndexByte(yylex.YYText, '\n')+1]
+				if !strings.HasSuffix(yylex.YYText, "\n"+terminator) {
+					// the heredoc continues
+					yylex.More()
+				} else {
+					yylex.Begin(INITIAL)
+					return Heredoc, nil
+				}
+			}
+//line action.go:584
+			goto yystart
+		case 8:
+//line action.l:42
+			{
+				// code span of Markdown such as ``a ` b`` ends with the same number of backticks as the beginning
+				_, err := yylex.ReadUntil(yylex.YYText)
+				return CodeSpan, err
+			}
+//line action.go:593
+		case 9:
+//line action.l:47
+			{
+				// nested comment
+				for depth := 1; depth > 0; {
+					r, _, err := yylex.ReadRune()
+					if err != nil {
+						return 0, ErrUnterminatedComment
+					}
+					if next, _ := yylex.PeekRune(); r == '/' && next == '*' {
+						yylex.ReadRune()
+						depth++
+					} else if r == '*' && next == '/' {
+						yylex.ReadRune()
+						depth--
+					}
+				}
+				return Comment, nil
+			}
+//line action.go:613
+		case 10:
+//line action.l:64
+			{
+				// length-prefixed binary field such as 3:abc
+				n, _ := strconv.Atoi(yylex.YYText[:len(yylex.YYText)-1])
+				_, err := yylex.Consume(n)
+				return Blob, err
+			}
+//line action.go:622
+		case 11:
+			goto yystart
+
+		default:
+			return 0, ErrYYScan
+		}
+	}
+
+	yylex.more = false
+	yylex.text = nil
+	yylex.YYText = ""
+	yylex.start = yylex.in.position
+	yylex.end = yylex.in.position
+	if yylex.PopInput() {
+		goto yystart
+	}
+
+	return 0, io.EOF
+}
+
+// scanError returns the error of runes no rule matches when the lexer gets stuck.
+// It must be called before the runes are skipped.
+func (yylex *yyLexer) scanError() *ScanError {
+	e := &ScanError{
+		Pos:       yylex.in.position,
+		Partial:   string(yylex.in.buf[yylex.beginPos:yylex.currPos]),
+		Rune:      -1,
+		Condition: yylex.cond,
+	}
+	if yylex.currPos < len(yylex.in.buf) {
+		e.Rune, _ = utf8.DecodeRune(yylex.in.buf[yylex.currPos:])
+	}
+
+	return e
+}
+
+// recoverError handles the scan error err after the runes no rule matches are skipped.
+// It returns nil if lexing continues.
+func (yylex *yyLexer) recoverError(err error) error {
+	if yylex.OnError != nil {
+		if err = yylex.OnError(err); err == nil {
+			return nil
+		}
+	}
+
+	return err
+}
+
+// NextToken is the same as Next, but returns the token with its text and positions.
+// With ErrYYScan the token has the text and positions of the rune no rule matched,
+// and with io.EOF it has the position of the end of the input.
+func (yylex *yyLexer) NextToken() (Token, error) {
+	kind, err := yylex.Next()
+	return Token{
+		Kind:  kind,
+		Text:  yylex.YYText,
+		Start: yylex.start,
+		End:   yylex.end,
+	}, err
+}
+
+// Tokens lexes the rest of the input and returns the tokens.
+// It stops at the first error other than io.EOF and returns the tokens before it with the error.
+func (yylex *yyLexer) Tokens() ([]Token, error) {
+	toks := make([]Token, 0)
+	for {
+		tok, err := yylex.NextToken()
+		if errors.Is(err, io.EOF) {
+			return toks, nil
+		} else if err != nil {
+			return toks, err
+		}
+		toks = append(toks, tok)
+	}
+}
+
+// Each calls yield with the rest of the tokens in order until yield returns false.
+// An error other than io.EOF is passed to yield and ends the iteration.
+// When yield stops the iteration, the lexer is just after the last token passed to yield,
+// so lexing can be resumed by Next, NextToken or Each.
+// It is the fallback of All for Go older than 1.23.
+func (yylex *yyLexer) Each(yield func(Token, error) bool) {
+	for {
+		tok, err := yylex.NextToken()
+		if errors.Is(err, io.EOF) {
+			return
+		}
+		if !yield(tok, err) || err != nil {
+			return
+		}
+	}
+}
+
+// Less pushes back the current token except its first n bytes, and they are scanned again.
+// It corresponds to yyless of lex. n must be between 0 and len(Bytes()), and
+// it returns ErrYYNegativeCount or ErrYYLongCount without changing anything otherwise.
+// Less(0) without changing the start condition makes an infinite loop.
+func (yylex *yyLexer) Less(n int) error {
+	if n < 0 {
+		return ErrYYNegativeCount
+	} else if n > len(yylex.text) {
+		return ErrYYLongCount
+	}
+	yylex.text = yylex.text[:n]
+	yylex.YYText = yylex.YYText[:n]
+	yylex.beginPos = yylex.textPos + n
+	yylex.finPos = yylex.beginPos
+	yylex.currPos = yylex.beginPos
+	yylex.in.position = yylex.start
+	yylex.in.cr = yylex.startCR
+	yylex.in.advance(yylex.text)
+	yylex.end = yylex.in.position
+
+	return nil
+}
+
+// PeekRune returns the next rune of the input without consuming it.
+// It returns io.EOF at the end of the input.
+func (yylex *yyLexer) PeekRune() (rune, error) {
+	r, _, err := yylex.currRune()
+	// reading may move the buffer
+	yylex.text = yylex.in.buf[yylex.textPos:yylex.beginPos]
+
+	return r, err
+}
+
+// ReadRune reads the next rune of the input and appends it to the current token.
+// It returns io.ErrUnexpectedEOF at the end of the input because the current token is unterminated,
+// so returning the error from the action does not end lexing silently.
+func (yylex *yyLexer) ReadRune() (rune, int, error) {
+	r, size, err := yylex.currRune()
+	if err != nil {
+		yylex.text = yylex.in.buf[yylex.textPos:yylex.beginPos]
+		if errors.Is(err, io.EOF) {
+			err = io.ErrUnexpectedEOF
+		}
+		return 0, 0, err
+	}
+	yylex.extend(size)
+
+	return r, size, nil
+}
+
+// ReadUntil reads the input until the first occurrence of delim and appends it to the current token.
+// It returns the read text including delim.
+// If the input ends before delim, it reads the rest and returns io.ErrUnexpectedEOF.
+func (yylex *yyLexer) ReadUntil(delim string) (string, error) {
+	d := yyStringBytes(delim)
+	searched := 0 // number of bytes from the read position which do not begin delim
+	for {
+		rest := yylex.in.buf[yylex.beginPos:]
+		if i := bytes.Index(rest[searched:], d); i >= 0 {
+			n := searched + i + len(d)
+			s := yylex.textString(rest[:n])
+			yylex.extend(n)
+			return s, nil
+		}
+		if len(rest) >= len(d) {
+			searched = len(rest) - len(d) + 1
+		}
+		if err := yylex.fill(); err != nil {
+			s := yylex.textString(yylex.in.buf[yylex.beginPos:])
+			yylex.extend(len(yylex.in.buf) - yylex.beginPos)
+			if errors.Is(err, io.EOF) {
+				err = io.ErrUnexpectedEOF
+			}
+			return s, err
+		}
+	}
+}
+
+// Consume reads n bytes of the input and appends them to the current token.
+// If the input ends before n bytes, it reads the rest and returns io.ErrUnexpectedEOF.
+// It returns the number of bytes read, and ErrYYNegativeCount if n is negative.
+func (yylex *yyLexer) Consume(n int) (int, error) {
+	if n < 0 {
+		return 0, ErrYYNegativeCount
+	}
+	for len(yylex.in.buf)-yylex.beginPos < n {
+		if err := yylex.fill(); err != nil {
+			m := len(yylex.in.buf) - yylex.beginPos
+			yylex.extend(m)
+			if errors.Is(err, io.EOF) {
+				err = io.ErrUnexpectedEOF
+			}
+			return m, err
+		}
+	}
+	yylex.extend(n)
+
+	return n, nil
+}
+
+// extend appends n bytes at the read position to the current token.
+func (yylex *yyLexer) extend(n int) {
+	yylex.in.advance(yylex.in.buf[yylex.beginPos : yylex.beginPos+n])
+	yylex.beginPos += n
+	yylex.finPos = yylex.beginPos
+	yylex.currPos = yylex.beginPos
+	yylex.text = yylex.in.buf[yylex.textPos:yylex.beginPos]
+	yylex.YYText = yylex.textString(yylex.text)
+	yylex.end = yylex.in.position
+}
+
+// More makes the next token appended to the current text instead of replacing it.
+// It corresponds to yymore of lex. Pos() of the next token is the beginning of the current one.
+// The current text is dropped if the next runes match no rule or the input is switched.
+func (yylex *yyLexer) More() {
+	yylex.more = true
+}
+
+// Unput pushes s back to the input, and it is scanned before the rest of the input.
+// It corresponds to unput of lex. Positions count s as a part of the input.
+// The input is never modified; the current text, s and the buffered bytes are copied to a new buffer.
+// The rest of an in-memory input is read through a reader afterwards instead of being copied,
+// so texts of the following tokens do not share the memory with the input.
+func (yylex *yyLexer) Unput(s string) {
+	in := &yylex.in
+	n := len(yylex.text)
+	rest := in.buf[yylex.beginPos:]
+	if in.r == nil {
+		in.r = bytes.NewReader(rest)
+		in.err = nil
+		rest = nil
+	}
+	buf := make([]byte, 0, n+len(s)+len(rest)+yyBufSize)
+	buf = append(buf, in.buf[yylex.textPos:yylex.beginPos]...)
+	buf = append(buf, s...)
+	buf = append(buf, rest...)
+	in.buf = buf
+	yylex.text = buf[:n]
+	yylex.textPos = 0
+	yylex.beginPos = n
+	yylex.finPos = n
+	yylex.currPos = n
+}
+
+// Begin switches the start condition. It corresponds to BEGIN of lex.
+func (yylex *yyLexer) Begin(cond int) {
+	yylex.cond = cond
+	yylex.currStateID = yyStartStates[cond]
+}
+
+// StartCondition returns the current start condition.
+func (yylex *yyLexer) StartCondition() int {
+	return yylex.cond
+}
+
+// PushState saves the current start condition on the stack and switches to cond.
+// It corresponds to yy_push_state of flex.
+func (yylex *yyLexer) PushState(cond int) {
+	yylex.condStack = append(yylex.condStack, yylex.cond)
+	yylex.Begin(cond)
+}
+
+// PopState switches to the start condition on the top of the stack and removes it.
+// It returns ErrYYEmptyStack if the stack is empty.
+func (yylex *yyLexer) PopState() error {
+	n := len(yylex.condStack)
+	if n == 0 {
+		return ErrYYEmptyStack
+	}
+	cond := yylex.condStack[n-1]
+	yylex.condStack = yylex.condStack[:n-1]
+	yylex.Begin(cond)
+
+	return nil
+}
+
+// TopState returns the start condition on the top of the stack.
+// It returns ErrYYEmptyStack if the stack is empty.
+func (yylex *yyLexer) TopState() (int, error) {
+	n := len(yylex.condStack)
+	if n == 0 {
+		return 0, ErrYYEmptyStack
+	}
+
+	return yylex.condStack[n-1], nil
+}
+
+// StateStack returns a copy of the start condition stack. The last element is the top.
+func (yylex *yyLexer) StateStack() []int {
+	return append([]int{}, yylex.condStack...)
+}
+
+// PushInput suspends the current input and starts reading r.
+// The suspended input is resumed when r is exhausted or PopInput is called.
+func (yylex *yyLexer) PushInput(r io.Reader) {
+	in := yylex.in
+	in.pos = yylex.currPos
+	yylex.inputStack = append(yylex.inputStack, in)
+	yylex.in = yyInput{r: r, position: yyStartPosition}
+	yylex.textPos = 0
+	yylex.more = false
+	yylex.beginPos = 0
+	yylex.finPos = 0
+	yylex.currPos = 0
+	yylex.finRegexID = 0
+	yylex.currStateID = yyStartStates[yylex.cond]
+	yylex.inputID++
+}
+
+// PopInput discards the current input and resumes the last suspended one.
+// It returns false if there is no suspended input.
+func (yylex *yyLexer) PopInput() bool {
+	n := len(yylex.inputStack)
+	if n == 0 {
+		return false
+	}
+	yylex.in = yylex.inputStack[n-1]
+	yylex.inputStack = yylex.inputStack[:n-1]
+	yylex.textPos = yylex.in.pos
+	yylex.more = false
+	yylex.beginPos = yylex.in.pos
+	yylex.finPos = yylex.in.pos
+	yylex.currPos = yylex.in.pos
+	yylex.finRegexID = 0
+	yylex.currStateID = yyStartStates[yylex.cond]
+	yylex.inputID++
+
+	return true
+}
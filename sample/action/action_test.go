@@ -0,0 +1,191 @@
+package action
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"testing/iotest"
+)
+
+type token struct {
+	kind       TokenKind
+	text       string
+	start, end string
+}
+
+func lexers(given string) map[string]*yyLexer {
+	return map[string]*yyLexer{
+		"reader": New(iotest.OneByteReader(strings.NewReader(given))),
+		"string": NewFromString(given),
+	}
+}
+
+func check(t *testing.T, lex *yyLexer, expected []token) {
+	t.Helper()
+
+	toks, err := lex.Tokens()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(toks) != len(expected) {
+		t.Fatalf("expected %v tokens but %+v", len(expected), toks)
+	}
+	for i, e := range expected {
+		tok := toks[i]
+		if tok.Kind != e.kind || tok.Text != e.text || tok.Start.String() != e.start || tok.End.String() != e.end {
+			t.Errorf("expected %v %q %v-%v but %v %q %v-%v", e.kind, e.text, e.start, e.end, tok.Kind, tok.Text, tok.Start, tok.End)
+		}
+	}
+}
+
+func TestLess(t *testing.T) {
+	expected := []token{
+		{Ident, "a", "1:1", "1:2"},
+		{Greater, ">", "1:2", "1:3"},
+		{Greater, ">", "1:3", "1:4"},
+		{Ident, "b", "1:4", "1:5"},
+		{Greater, ">", "2:1", "2:2"},
+	}
+	for name, lex := range lexers("a>>b\n>") {
+		t.Run(name, func(t *testing.T) {
+			check(t, lex, expected)
+		})
+	}
+}
+
+func TestMore(t *testing.T) {
+	expected := []token{
+		{Ident, "x", "1:1", "1:2"},
+		{Heredoc, "<<END\nfoo\n>>\nEND\n", "1:3", "5:1"},
+		{Heredoc, "<<E\nE\n", "5:1", "7:1"},
+		{Ident, "y", "7:1", "7:2"},
+	}
+	for name, lex := range lexers("x <<END\nfoo\n>>\nEND\n<<E\nE\ny") {
+		t.Run(name, func(t *testing.T) {
+			check(t, lex, expected)
+		})
+	}
+}
+
+func TestUnput(t *testing.T) {
+	// positions count the pushed back runes as input.
+	expected := []token{
+		{Ident, "a", "1:1", "1:2"},
+		{Ident, "foo", "1:5", "1:8"},
+		{Number, "12", "1:9", "1:11"},
+		{Greater, ">", "1:15", "1:16"},
+		{Greater, ">", "1:16", "1:17"},
+		{Ident, "b", "1:18", "1:19"},
+	}
+	for name, lex := range lexers("a $x $gt b") {
+		t.Run(name, func(t *testing.T) {
+			check(t, lex, expected)
+		})
+	}
+}
+
+func TestUnput_InputIsNotModified(t *testing.T) {
+	given := []byte("a $x $gt b")
+	lex := NewFromBytes(given[:4])
+	if _, err := lex.Tokens(); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(given, []byte("a $x $gt b")) {
+		t.Errorf("the input is modified: %q", given)
+	}
+}
+
+func TestUnput_LongInput(t *testing.T) {
+	// Unput does not copy the rest of an in-memory input, which makes this quadratic.
+	n := 100000
+	given := strings.Repeat("$gt ", n) + "end"
+	lex := NewFromString(given)
+	first, err := lex.NextToken()
+	if err != nil {
+		t.Fatal(err)
+	}
+	toks, err := lex.Tokens()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if first.Text != ">" || len(toks) != 2*n {
+		t.Fatalf("expected %v tokens but %v %+v", 2*n+1, len(toks)+1, first)
+	}
+	// positions count ">>" pushed by Unput as input.
+	if last := toks[len(toks)-1]; last.Kind != Ident || last.Text != "end" || last.Start.Column != 6*n+1 {
+		t.Errorf("expected end but %+v", last)
+	}
+}
+
+func TestReadUntil(t *testing.T) {
+	expected := []token{
+		{CodeSpan, "``a ` b``", "1:1", "1:10"},
+		{CodeSpan, "`x\ny`", "1:11", "2:3"},
+		{Ident, "c", "2:4", "2:5"},
+	}
+	for name, lex := range lexers("``a ` b`` `x\ny` c") {
+		t.Run(name, func(t *testing.T) {
+			check(t, lex, expected)
+		})
+	}
+
+	lex := NewFromString("a ``b` c")
+	lex.Next()
+	tok, err := lex.NextToken()
+	if !errors.Is(err, io.ErrUnexpectedEOF) || tok.Text != "``b` c" || tok.End.Column != 9 {
+		t.Errorf("expected the unterminated code span but %+v %v", tok, err)
+	}
+}
+
+func TestReadRune(t *testing.T) {
+	expected := []token{
+		{Ident, "a", "1:1", "1:2"},
+		{Comment, "/* b /* c */\n*/", "1:3", "2:3"},
+		{Ident, "d", "2:4", "2:5"},
+	}
+	for name, lex := range lexers("a /* b /* c */\n*/ d") {
+		t.Run(name, func(t *testing.T) {
+			check(t, lex, expected)
+		})
+	}
+
+	_, err := NewFromString("/* /* */").Tokens()
+	if !errors.Is(err, ErrUnterminatedComment) {
+		t.Errorf("expected ErrUnterminatedComment but %v", err)
+	}
+}
+
+func TestConsume(t *testing.T) {
+	expected := []token{
+		{Blob, "5:ab\ncd", "1:1", "2:3"},
+		{Blob, "0:", "2:4", "2:6"},
+		{Ident, "e", "2:6", "2:7"},
+	}
+	for name, lex := range lexers("5:ab\ncd 0:e") {
+		t.Run(name, func(t *testing.T) {
+			check(t, lex, expected)
+		})
+	}
+
+	lex := NewFromString("9:abc")
+	tok, err := lex.NextToken()
+	if !errors.Is(err, io.ErrUnexpectedEOF) || tok.Text != "9:abc" {
+		t.Errorf("expected the short blob but %+v %v", tok, err)
+	}
+}
+
+func TestReadRune_LongToken(t *testing.T) {
+	// the token is longer than the buffer of the lexer, which is refilled while reading it.
+	comment := "/*" + strings.Repeat("ab\n", 5000) + "*/"
+	lex := New(iotest.HalfReader(strings.NewReader(comment + " x")))
+	tok, err := lex.NextToken()
+	if err != nil || tok.Text != comment || tok.End.Line != 5001 {
+		t.Fatalf("expected the comment but %q %v %v", tok.Text[:10], tok.End, err)
+	}
+	tok, err = lex.NextToken()
+	if err != nil || tok.Text != "x" {
+		t.Errorf("expected x but %+v %v", tok, err)
+	}
+}
@@ -0,0 +1,115 @@
+package condition
+
+import (
+	"errors"
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestStartCondition(t *testing.T) {
+	tests := []struct {
+		name     string
+		given    string
+		expected []TokenKind
+		texts    []string
+		err      error
+	}{
+		{
+			name:     "string",
+			given:    `ab 'cd /* ef */ g\nh' ij`,
+			expected: []TokenKind{Ident, Str, Ident},
+			texts:    []string{"ab", "cd /* ef */ g\nh", "ij"},
+		},
+		{
+			name:     "comment",
+			given:    "ab /* 'cd'\n ef */ gh",
+			expected: []TokenKind{Ident, Ident},
+			texts:    []string{"ab", "gh"},
+		},
+		{
+			name:     "unterminated string",
+			given:    "ab 'cd",
+			expected: []TokenKind{Ident},
+			texts:    []string{"ab"},
+			err:      ErrUnterminatedString,
+		},
+		{
+			name:     "unterminated comment",
+			given:    "ab /* cd",
+			expected: []TokenKind{Ident},
+			texts:    []string{"ab"},
+			err:      ErrUnterminatedComment,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			lex := New(strings.NewReader(tt.given))
+			kinds := make([]TokenKind, 0)
+			texts := make([]string, 0)
+			var err error
+			for {
+				var n TokenKind
+				n, err = lex.Next()
+				if err != nil {
+					break
+				}
+				kinds = append(kinds, n)
+				if n == Str {
+					texts = append(texts, StrValue(lex.YYText))
+				} else {
+					texts = append(texts, lex.YYText)
+				}
+			}
+
+			if tt.err != nil {
+				if !errors.Is(err, tt.err) {
+					t.Fatalf("expected %v but %v", tt.err, err)
+				}
+			} else if !errors.Is(err, io.EOF) {
+				t.Fatalf("expected io.EOF but %v", err)
+			}
+			if len(tt.expected) != len(kinds) {
+				t.Fatalf("expected %v but %v", tt.expected, kinds)
+			}
+			for i, v := range tt.expected {
+				if v != kinds[i] {
+					t.Errorf("type is different: expected %v but %v", v, kinds[i])
+				}
+				if tt.texts[i] != texts[i] {
+					t.Errorf("token is different: expected %q but %q", tt.texts[i], texts[i])
+				}
+			}
+		})
+	}
+}
+
+func TestScanError(t *testing.T) {
+	lex := NewFromString("ab 'c\\x'")
+	if _, err := lex.Next(); err != nil {
+		t.Fatal(err)
+	}
+	_, err := lex.Next()
+
+	var scanErr *ScanError
+	if !errors.As(err, &scanErr) {
+		t.Fatalf("expected ScanError but %v", err)
+	}
+	if !errors.Is(err, ErrYYScan) {
+		t.Errorf("expected to wrap ErrYYScan but %v", err)
+	}
+	expected := ScanError{
+		Pos:       Position{Offset: 5, Line: 1, Column: 6, ByteColumn: 6},
+		Text:      `\`,
+		Partial:   `\`,
+		Rune:      'x',
+		Condition: STR,
+	}
+	if *scanErr != expected {
+		t.Errorf("expected %+v but %+v", expected, *scanErr)
+	}
+	if msg := `1:6: failed to scan "\\" in start condition STR`; err.Error() != msg {
+		t.Errorf("expected %q but %q", msg, err.Error())
+	}
+}
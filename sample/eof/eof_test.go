@@ -0,0 +1,81 @@
+package eof
+
+import (
+	"errors"
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestEOFRule(t *testing.T) {
+	tests := []struct {
+		name     string
+		given    string
+		expected []TokenKind
+		texts    []string
+		err      error
+	}{
+		{
+			name:     "final token",
+			given:    "ab cd",
+			expected: []TokenKind{Word, Word, End},
+			texts:    []string{"ab", "cd", ""},
+		},
+		{
+			name:     "push input",
+			given:    "@b q",
+			expected: []TokenKind{Word, Word, Word, Word, End},
+			texts:    []string{"x", "y", "z", "q", ""},
+		},
+		{
+			name:     "string",
+			given:    "'ab c' d",
+			expected: []TokenKind{String, Word, End},
+			texts:    []string{"'ab c'", "d", ""},
+		},
+		{
+			name:     "unterminated string",
+			given:    "ab 'cd",
+			expected: []TokenKind{Word},
+			texts:    []string{"ab"},
+			err:      ErrUnterminated,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			lex := New(strings.NewReader(tt.given))
+			kinds := make([]TokenKind, 0)
+			texts := make([]string, 0)
+			var err error
+			for {
+				var n TokenKind
+				n, err = lex.Next()
+				if err != nil {
+					break
+				}
+				kinds = append(kinds, n)
+				texts = append(texts, lex.YYText)
+			}
+
+			if tt.err != nil {
+				if !errors.Is(err, tt.err) {
+					t.Fatalf("expected %v but %v", tt.err, err)
+				}
+			} else if !errors.Is(err, io.EOF) {
+				t.Fatalf("expected io.EOF but %v", err)
+			}
+			if len(tt.expected) != len(kinds) {
+				t.Fatalf("expected %v but %v", tt.expected, kinds)
+			}
+			for i, v := range tt.expected {
+				if v != kinds[i] {
+					t.Errorf("type is different: expected %v but %v", v, kinds[i])
+				}
+				if tt.texts[i] != texts[i] {
+					t.Errorf("token is different: expected %q but %q", tt.texts[i], texts[i])
+				}
+			}
+		})
+	}
+}
@@ -4,7 +4,9 @@ import (
 	"bytes"
 	"errors"
 	"io"
+	"strings"
 	"testing"
+	"testing/iotest"
 )
 
 func TestLexer(t *testing.T) {
@@ -17,11 +19,11 @@ func foo000あいう() int {
 `
 
 	lex := New(bytes.NewReader([]byte(program)))
-	ns := make([]int, 0)
+	ns := make([]TokenKind, 0)
 	strs := make([]string, 0)
 
 	expected := []struct {
-		typ  int
+		typ  TokenKind
 		text string
 	}{
 		{Keyword, "func"},
@@ -75,3 +77,231 @@ func foo000あいう() int {
 	// 	}
 	// }
 }
+
+// lexAll returns kinds and texts of all tokens.
+func lexAll(lex *yyLexer) ([]TokenKind, []string, error) {
+	kinds := make([]TokenKind, 0)
+	texts := make([]string, 0)
+	for {
+		kind, err := lex.Next()
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				return kinds, texts, nil
+			}
+			return kinds, texts, err
+		}
+		kinds = append(kinds, kind)
+		texts = append(texts, lex.YYText)
+	}
+}
+
+func TestLexer_Reader(t *testing.T) {
+	program := strings.Repeat("func foo000あいう() int {\n    return 1 * 10 + 123\n}\n", 50)
+	expectedKinds, expectedTexts, err := lexAll(New(strings.NewReader(program)))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name string
+		r    func() io.Reader
+	}{
+		{name: "one byte reader", r: func() io.Reader { return iotest.OneByteReader(strings.NewReader(program)) }},
+		{name: "half reader", r: func() io.Reader { return iotest.HalfReader(strings.NewReader(program)) }},
+		{name: "data and EOF together", r: func() io.Reader { return iotest.DataErrReader(strings.NewReader(program)) }},
+		{
+			name: "pipe",
+			r: func() io.Reader {
+				pr, pw := io.Pipe()
+				go func() {
+					for _, line := range strings.SplitAfter(program, "\n") {
+						pw.Write([]byte(line))
+					}
+					pw.Close()
+				}()
+				return pr
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			kinds, texts, err := lexAll(New(tt.r()))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(kinds) != len(expectedKinds) {
+				t.Fatalf("expected %v tokens but %v", len(expectedKinds), len(kinds))
+			}
+			for i := range kinds {
+				if kinds[i] != expectedKinds[i] || texts[i] != expectedTexts[i] {
+					t.Errorf("expected %v %q but %v %q", expectedKinds[i], expectedTexts[i], kinds[i], texts[i])
+				}
+			}
+		})
+	}
+}
+
+func TestLexer_InMemory(t *testing.T) {
+	program := "func foo000あいう() int {\n    return 1 * 10 + 123\n}\n"
+	expectedKinds, expectedTexts, err := lexAll(New(strings.NewReader(program)))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name string
+		lex  *yyLexer
+	}{
+		{name: "bytes", lex: NewFromBytes([]byte(program))},
+		{name: "string", lex: NewFromString(program)},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			for i := range expectedKinds {
+				kind, err := tt.lex.Next()
+				if err != nil {
+					t.Fatal(err)
+				}
+				if kind != expectedKinds[i] || tt.lex.Text() != expectedTexts[i] || string(tt.lex.Bytes()) != expectedTexts[i] {
+					t.Errorf("expected %v %q but %v %q %q", expectedKinds[i], expectedTexts[i], kind, tt.lex.Text(), tt.lex.Bytes())
+				}
+			}
+			if _, err := tt.lex.Next(); !errors.Is(err, io.EOF) {
+				t.Errorf("expected EOF but %v", err)
+			}
+		})
+	}
+}
+
+func TestLexer_InMemoryAllocs(t *testing.T) {
+	program := strings.Repeat("func foo000あいう() int {\n    return 1 * 10 + 123\n}\n", 100)
+	data := []byte(program)
+
+	tests := []struct {
+		name string
+		new  func() *yyLexer
+	}{
+		{name: "bytes", new: func() *yyLexer { return NewFromBytes(data) }},
+		{name: "string", new: func() *yyLexer { return NewFromString(program) }},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			allocs := testing.AllocsPerRun(10, func() {
+				lex := tt.new()
+				for {
+					if _, err := lex.Next(); err != nil {
+						break
+					}
+				}
+			})
+			// only the lexer itself is allocated.
+			if allocs > 1 {
+				t.Errorf("lexing allocates %v times", allocs)
+			}
+		})
+	}
+}
+
+func TestLexer_ReadError(t *testing.T) {
+	errRead := errors.New("read error")
+	lex := New(io.MultiReader(strings.NewReader("func x"), iotest.ErrReader(errRead)))
+	kinds, _, err := lexAll(lex)
+	if !errors.Is(err, errRead) {
+		t.Fatalf("expected %v but %v", errRead, err)
+	}
+	// x may continue in the unread input, so only func is returned.
+	if len(kinds) != 1 {
+		t.Errorf("expected 1 token before the error but %v", len(kinds))
+	}
+}
+
+func TestLexer_BufferIsBoundedByToken(t *testing.T) {
+	program := strings.Repeat("foo 123 ", 100000)
+	lex := New(strings.NewReader(program))
+	if _, _, err := lexAll(lex); err != nil {
+		t.Fatal(err)
+	}
+	if cap(lex.in.buf) > yyBufSize {
+		t.Errorf("buffer grows to %v bytes for short tokens", cap(lex.in.buf))
+	}
+
+	long := strings.Repeat("a", 3*yyBufSize)
+	lex = New(strings.NewReader("x " + long + " y"))
+	_, texts, err := lexAll(lex)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(texts) != 3 || texts[1] != long {
+		t.Errorf("the long token is not recognized: %v tokens", len(texts))
+	}
+}
+
+func benchmarkProgram() []byte {
+	return bytes.Repeat([]byte(`
+func foo000あいう() int {
+    x := 1 * 10 + 123 - 1000 / 5432
+
+    return x
+}
+`), 100)
+}
+
+func BenchmarkLexer(b *testing.B) {
+	program := benchmarkProgram()
+	b.SetBytes(int64(len(program)))
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		lex := New(bytes.NewReader(program))
+		for {
+			if _, err := lex.Next(); err != nil {
+				if errors.Is(err, io.EOF) {
+					break
+				}
+				b.Fatal(err)
+			}
+		}
+	}
+}
+
+func BenchmarkLexer_FromBytes(b *testing.B) {
+	program := benchmarkProgram()
+	b.SetBytes(int64(len(program)))
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		lex := NewFromBytes(program)
+		for {
+			if _, err := lex.Next(); err != nil {
+				if errors.Is(err, io.EOF) {
+					break
+				}
+				b.Fatal(err)
+			}
+		}
+	}
+}
+
+func BenchmarkLexer_FromString(b *testing.B) {
+	program := string(benchmarkProgram())
+	b.SetBytes(int64(len(program)))
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		lex := NewFromString(program)
+		for {
+			if _, err := lex.Next(); err != nil {
+				if errors.Is(err, io.EOF) {
+					break
+				}
+				b.Fatal(err)
+			}
+		}
+	}
+}
@@ -0,0 +1,33 @@
+//go:build go1.23
+
+package position
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestAll(t *testing.T) {
+	lex := NewFromString("foo 12 bar 34")
+	texts := make([]string, 0)
+	for tok, err := range lex.All() {
+		if err != nil {
+			t.Fatal(err)
+		}
+		if tok.Kind == Number {
+			break
+		}
+		texts = append(texts, tok.Text)
+	}
+	for tok, err := range lex.All() {
+		if err != nil {
+			t.Fatal(err)
+		}
+		texts = append(texts, tok.Text)
+	}
+
+	expected := []string{"foo", "bar", "34"}
+	if !reflect.DeepEqual(texts, expected) {
+		t.Errorf("expected %q but %q", expected, texts)
+	}
+}
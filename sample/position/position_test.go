@@ -0,0 +1,302 @@
+package position
+
+import (
+	"errors"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+	"testing/iotest"
+)
+
+func TestPosition(t *testing.T) {
+	given := "foo あいう 12\r\nbar\r`a\nb`\n  x"
+	expected := []struct {
+		kind       TokenKind
+		text       string
+		start, end Position
+	}{
+		{Ident, "foo", Position{0, 1, 1, 1, 1}, Position{3, 1, 4, 4, 4}},
+		{Ident, "あいう", Position{4, 1, 5, 5, 5}, Position{13, 1, 8, 14, 11}},
+		{Number, "12", Position{14, 1, 9, 15, 12}, Position{16, 1, 11, 17, 14}},
+		{Ident, "bar", Position{18, 2, 1, 1, 1}, Position{21, 2, 4, 4, 4}},
+		{RawString, "`a\nb`", Position{22, 3, 1, 1, 1}, Position{27, 4, 3, 3, 3}},
+		{Ident, "x", Position{30, 5, 3, 3, 3}, Position{31, 5, 4, 4, 4}},
+	}
+
+	tests := []struct {
+		name string
+		lex  *yyLexer
+	}{
+		{name: "reader", lex: New(strings.NewReader(given))},
+		{name: "string", lex: NewFromString(given)},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			for _, e := range expected {
+				kind, err := tt.lex.Next()
+				if err != nil {
+					t.Fatal(err)
+				}
+				if kind != e.kind || tt.lex.YYText != e.text {
+					t.Fatalf("expected %v %q but %v %q", e.kind, e.text, kind, tt.lex.YYText)
+				}
+				if tt.lex.Pos() != e.start || tt.lex.EndPos() != e.end {
+					t.Errorf("%q: expected %+v-%+v but %+v-%+v", e.text, e.start, e.end, tt.lex.Pos(), tt.lex.EndPos())
+				}
+				if tt.lex.YYLineno != e.end.Line {
+					t.Errorf("%q: expected YYLineno %v but %v", e.text, e.end.Line, tt.lex.YYLineno)
+				}
+			}
+			if _, err := tt.lex.Next(); !errors.Is(err, io.EOF) {
+				t.Fatalf("expected EOF but %v", err)
+			}
+			end := Position{31, 5, 4, 4, 4}
+			if tt.lex.Pos() != end || tt.lex.EndPos() != end {
+				t.Errorf("expected %+v at EOF but %+v-%+v", end, tt.lex.Pos(), tt.lex.EndPos())
+			}
+		})
+	}
+}
+
+func TestTokens(t *testing.T) {
+	given := "foo あいう\n  12 " + strings.Repeat("a", 5000)
+	expected := []Token{
+		{Ident, "foo", Position{0, 1, 1, 1, 1}, Position{3, 1, 4, 4, 4}},
+		{Ident, "あいう", Position{4, 1, 5, 5, 5}, Position{13, 1, 8, 14, 11}},
+		{Number, "12", Position{16, 2, 3, 3, 3}, Position{18, 2, 5, 5, 5}},
+		{Ident, strings.Repeat("a", 5000), Position{19, 2, 6, 6, 6}, Position{5019, 2, 5006, 5006, 5006}},
+	}
+
+	tests := []struct {
+		name string
+		lex  *yyLexer
+	}{
+		{name: "reader", lex: New(iotest.OneByteReader(strings.NewReader(given)))},
+		{name: "string", lex: NewFromString(given)},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			toks, err := tt.lex.Tokens()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(toks, expected) {
+				t.Errorf("expected %+v but %+v", expected, toks)
+			}
+		})
+	}
+}
+
+func TestNextToken_Error(t *testing.T) {
+	lex := NewFromString("foo !")
+	tok, err := lex.NextToken()
+	if err != nil || tok.Kind != Ident || tok.Text != "foo" {
+		t.Fatalf("expected foo but %+v %v", tok, err)
+	}
+	tok, err = lex.NextToken()
+	if !errors.Is(err, ErrYYScan) {
+		t.Fatalf("expected ErrYYScan but %v", err)
+	}
+	if tok.Text != "!" || tok.Start.Column != 5 || tok.End.Column != 6 {
+		t.Errorf("expected the unmatched rune but %+v", tok)
+	}
+	var scanErr *ScanError
+	if !errors.As(err, &scanErr) || scanErr.Text != "!" || scanErr.Pos != tok.Start || scanErr.Rune != '!' {
+		t.Errorf("expected ScanError of ! but %#v", err)
+	}
+	tok, err = lex.NextToken()
+	if !errors.Is(err, io.EOF) {
+		t.Fatalf("expected EOF but %v", err)
+	}
+	if tok.Start.Offset != 5 || tok.End.Offset != 5 {
+		t.Errorf("expected the end of the input but %+v", tok)
+	}
+
+	toks, err := NewFromString("foo ! bar").Tokens()
+	if !errors.Is(err, ErrYYScan) || len(toks) != 1 || toks[0].Text != "foo" {
+		t.Errorf("expected foo and ErrYYScan but %+v %v", toks, err)
+	}
+}
+
+func TestOnError(t *testing.T) {
+	lex := NewFromString("foo !? bar")
+	skipped := make([]string, 0)
+	lex.OnError = func(err error) error {
+		skipped = append(skipped, lex.Text())
+		return nil
+	}
+	toks, err := lex.Tokens()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(toks) != 2 || toks[0].Text != "foo" || toks[1].Text != "bar" {
+		t.Errorf("expected foo and bar but %+v", toks)
+	}
+	if !reflect.DeepEqual(skipped, []string{"!", "?"}) {
+		t.Errorf("expected each unmatched rune but %q", skipped)
+	}
+}
+
+func TestEach(t *testing.T) {
+	lex := NewFromString("foo bar baz ! qux")
+	texts := make([]string, 0)
+	lex.Each(func(tok Token, err error) bool {
+		if err != nil {
+			t.Fatal(err)
+		}
+		texts = append(texts, tok.Text)
+		return len(texts) < 2
+	})
+	if !reflect.DeepEqual(texts, []string{"foo", "bar"}) {
+		t.Fatalf("expected to stop after bar but %q", texts)
+	}
+
+	// resumes after the last token, and an error ends the iteration.
+	var errs []error
+	lex.Each(func(tok Token, err error) bool {
+		if err != nil {
+			errs = append(errs, err)
+		} else {
+			texts = append(texts, tok.Text)
+		}
+		return true
+	})
+	if !reflect.DeepEqual(texts, []string{"foo", "bar", "baz"}) {
+		t.Errorf("expected to resume at baz but %q", texts)
+	}
+	if len(errs) != 1 || !errors.Is(errs[0], ErrYYScan) {
+		t.Errorf("expected one ErrYYScan but %v", errs)
+	}
+
+	tok, err := lex.NextToken()
+	if err != nil || tok.Text != "qux" {
+		t.Errorf("expected qux after the error but %+v %v", tok, err)
+	}
+}
+
+func TestPosition_CRLFAcrossTokens(t *testing.T) {
+	// CR and LF of CRLF are matched by different tokens.
+	lex := NewFromString("a\r\nb\n\rc")
+	lines := make([]int, 0)
+	for {
+		if _, err := lex.Next(); err != nil {
+			break
+		}
+		lines = append(lines, lex.Pos().Line)
+	}
+	expected := []int{1, 2, 4}
+	if len(lines) != len(expected) {
+		t.Fatalf("expected %v but %v", expected, lines)
+	}
+	for i := range expected {
+		if lines[i] != expected[i] {
+			t.Errorf("expected %v but %v", expected, lines)
+		}
+	}
+}
+
+func TestPosition_String(t *testing.T) {
+	if s := (Position{Offset: 10, Line: 2, Column: 3, ByteColumn: 5}).String(); s != "2:3" {
+		t.Errorf("expected 2:3 but %v", s)
+	}
+}
+
+func TestPosition_DisplayColumn(t *testing.T) {
+	tests := []struct {
+		name     string
+		given    string
+		tabWidth int
+		expected []int // display columns of the beginning of tokens
+	}{
+		{name: "wide", given: "あい a い b", tabWidth: 8, expected: []int{1, 6, 8, 11}},
+		{name: "tab", given: "\ta\tbb\tc", tabWidth: 8, expected: []int{9, 17, 25}},
+		{name: "tab width 4", given: "\ta\tbb\tc", tabWidth: 4, expected: []int{5, 9, 13}},
+		{name: "tab after wide", given: "あ\tb", tabWidth: 4, expected: []int{1, 5}},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			lex := NewFromString(tt.given)
+			lex.TabWidth = tt.tabWidth
+			cols := make([]int, 0)
+			for {
+				if _, err := lex.Next(); err != nil {
+					break
+				}
+				cols = append(cols, lex.Pos().DisplayColumn)
+			}
+			if len(cols) != len(tt.expected) {
+				t.Fatalf("expected %v but %v", tt.expected, cols)
+			}
+			for i := range cols {
+				if cols[i] != tt.expected[i] {
+					t.Fatalf("expected %v but %v", tt.expected, cols)
+				}
+			}
+		})
+	}
+}
+
+func TestRuneWidth(t *testing.T) {
+	tests := []struct {
+		r        rune
+		expected int
+	}{
+		{'a', 1},
+		{'あ', 2},
+		{'漢', 2},
+		{'Ａ', 2},
+		{'ｱ', 1},
+		{'α', 1},
+		{'\u0301', 0}, // combining acute accent
+		{'\u200d', 0}, // zero width joiner
+		{'\x00', 0},
+		{'😀', 2},
+	}
+
+	for _, tt := range tests {
+		if w := RuneWidth(tt.r); w != tt.expected {
+			t.Errorf("%q: expected %v but %v", tt.r, tt.expected, w)
+		}
+	}
+}
+
+func TestCaret(t *testing.T) {
+	src := "foo\nx\tあいう bar\n`a\nb`"
+	tests := []struct {
+		name     string
+		text     string // token to mark
+		tabWidth int
+		expected string
+	}{
+		{name: "wide", text: "あいう", tabWidth: 4, expected: "x   あいう bar\n    ^^^^^^"},
+		{name: "ascii", text: "bar", tabWidth: 8, expected: "x       あいう bar\n               ^^^"},
+		{name: "first line", text: "foo", tabWidth: 8, expected: "foo\n^^^"},
+		{name: "multi-line token", text: "`a\nb`", tabWidth: 8, expected: "`a\n^^"},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			lex := NewFromString(src)
+			lex.TabWidth = tt.tabWidth
+			for {
+				if _, err := lex.Next(); err != nil {
+					t.Fatalf("%q is not found: %v", tt.text, err)
+				}
+				if lex.YYText == tt.text {
+					break
+				}
+			}
+			if got := Caret(src, lex.Pos(), lex.EndPos(), tt.tabWidth); got != tt.expected {
+				t.Errorf("expected\n%v\nbut\n%v", tt.expected, got)
+			}
+		})
+	}
+}
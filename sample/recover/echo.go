@@ -0,0 +1,743 @@
+// Code generated by tlex. DO NOT EDIT.
+
+package recover
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"unicode/utf8"
+	"unsafe"
+)
+
+type EchoTokenKind int
+
+const (
+	Digits EchoTokenKind = iota + 1
+)
+
+var echoTokenKindNames = [...]string{
+	0:      "",
+	Digits: "Digits",
+}
+
+func (k EchoTokenKind) String() string {
+	if 0 < k && int(k) < len(echoTokenKindNames) {
+		return echoTokenKindNames[k]
+	}
+	return fmt.Sprintf("EchoTokenKind(%d)", int(k))
+}
+
+// EchoTokenKindByName maps a token name to its kind.
+var EchoTokenKindByName = map[string]EchoTokenKind{
+	"Digits": Digits,
+}
+
+// names of start conditions
+var echoConditionNames = [...]string{
+	"INITIAL",
+}
+
+type echoStateID = int
+type echoRegexID = int
+
+var (
+	ErrEchoScan          = errors.New("failed to scan")
+	ErrEchoEmptyStack    = errors.New("start condition stack is empty")
+	ErrEchoNegativeCount = errors.New("negative count")
+	ErrEchoLongCount     = errors.New("count exceeds the current token")
+)
+
+// start state of each start condition
+var echoStartStates = []echoStateID{
+	1,
+}
+
+// state id to regex id
+var echoStateIDToRegexID = []echoRegexID{
+	0, // state 0 is dead state
+	9223372036854775807,
+	1,
+}
+
+var echoFinStates = []bool{
+	false,
+	false,
+	true,
+}
+
+// class of each rune less than 256. class 0 has no transition.
+var echoByteClasses = [256]int32{
+	0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0,
+	0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0,
+	0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0,
+	1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 0, 0, 0, 0, 0, 0,
+	0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0,
+	0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0,
+	0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0,
+	0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0,
+	0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0,
+	0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0,
+	0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0,
+	0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0,
+	0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0,
+	0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0,
+	0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0,
+	0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0,
+}
+
+// echoclassRange is the class of runes in [l, r].
+type echoclassRange struct {
+	l, r  int32
+	class int32
+}
+
+// classes of runes not less than 256 in ascending order of runes
+var echoClassRanges = []echoclassRange{}
+
+const echoNumClasses = 2
+
+// echoTransitions[s*echoNumClasses+c] is the next state of state s by a rune of class c.
+var echoTransitions = []echoStateID{
+	0, 0, // state 0 is dead state
+	0, 2,
+	0, 2,
+}
+
+func echoClassOf(r rune) int32 {
+	if 0 <= r && r < 256 {
+		return echoByteClasses[r]
+	}
+	i, j := 0, len(echoClassRanges)
+	for i < j {
+		h := int(uint(i+j) >> 1)
+		if echoClassRanges[h].r < r {
+			i = h + 1
+		} else {
+			j = h
+		}
+	}
+	if i < len(echoClassRanges) && echoClassRanges[i].l <= r {
+		return echoClassRanges[i].class
+	}
+
+	return 0
+}
+
+func echoNextStep(id echoStateID, r rune) echoStateID {
+	return echoTransitions[id*echoNumClasses+int(echoClassOf(r))]
+}
+
+func echoIsFinState(id echoStateID) bool {
+	return echoFinStates[id]
+}
+
+type echoLexer struct {
+	in          echoInput
+	beginPos    int // position of the current token in in.buf
+	finPos      int // end position of the longest match in in.buf
+	currPos     int // read position in in.buf
+	finRegexID  int
+	currStateID echoStateID
+	cond        int
+	condStack   []int
+	inputStack  []echoInput
+	inputID     int
+	textPos     int  // position of the text of the current token in in.buf
+	more        bool // the next token is appended to the current text
+	text        []byte
+	start       EchoPosition // position of the current token
+	startCR     bool         // in.cr at start
+	end         EchoPosition // position just after the current token
+	YYText      string
+	Out         io.Writer // destination of runes no rule matches, os.Stdout by default
+}
+
+// echoBufSize is the initial size of the input buffer.
+// The buffer grows when a token does not fit in it.
+const echoBufSize = 4096
+
+// EchoPosition is a position in the input.
+// A line ends with LF, CR or CRLF, and CRLF is one line break.
+type EchoPosition struct {
+	Offset     int // byte offset, starting at 0
+	Line       int // line number, starting at 1
+	Column     int // column in runes, starting at 1
+	ByteColumn int // column in bytes, starting at 1
+}
+
+// echoStartPosition is the position of the beginning of an input.
+var echoStartPosition = EchoPosition{Line: 1, Column: 1, ByteColumn: 1}
+
+func (p EchoPosition) String() string {
+	return fmt.Sprintf("%d:%d", p.Line, p.Column)
+}
+
+// EchoToken is a token returned by NextToken.
+// Unlike YYText, Text is not overwritten by later tokens.
+// With NewFromBytes, Text shares the memory with the input as YYText.
+type EchoToken struct {
+	Kind  EchoTokenKind
+	Text  string
+	Start EchoPosition // position of the beginning of the token
+	End   EchoPosition // position just after the token
+}
+
+// EchoScanError is the error of runes no rule matches.
+// It wraps ErrEchoScan.
+type EchoScanError struct {
+	Pos       EchoPosition // position of the skipped runes
+	Text      string       // skipped runes
+	Partial   string       // text read from Pos before the lexer got stuck, which no rule matches
+	Rune      rune         // rune no rule can continue with after Partial, or -1 at the end of the input
+	Condition int          // start condition
+}
+
+func (e *EchoScanError) Error() string {
+	msg := fmt.Sprintf("%v: %v %q", e.Pos, ErrEchoScan, e.Text)
+	if e.Condition != 0 {
+		msg += " in start condition " + echoConditionNames[e.Condition]
+	}
+
+	return msg
+}
+
+func (e *EchoScanError) Unwrap() error {
+	return ErrEchoScan
+}
+
+// echoInput is an input and the buffer of bytes read from it.
+// Bytes before the current token are discarded when the buffer is refilled,
+// so the buffer size is bounded by the longest token.
+// An in-memory input has no reader and its buffer is the whole input, which is never modified.
+type echoInput struct {
+	r   io.Reader
+	buf []byte
+	err error // error of the last read, which is reported after buf is consumed
+	pos int   // read position of a suspended input
+
+	position EchoPosition // position of the beginning of the current token
+	cr       bool         // the last byte before position is CR
+}
+
+// advance moves the position over b.
+func (in *echoInput) advance(b []byte) {
+	p := &in.position
+	p.Offset += len(b)
+	for _, c := range b {
+		switch {
+		case c == '\n' && in.cr:
+			// LF of CRLF. the line break is counted at CR.
+		case c == '\n' || c == '\r':
+			p.Line++
+			p.Column = 1
+			p.ByteColumn = 1
+		default:
+			p.ByteColumn++
+			if utf8.RuneStart(c) {
+				p.Column++
+			}
+		}
+		in.cr = c == '\r'
+	}
+}
+
+func NewEcho(r io.Reader) *echoLexer {
+	return echoNewLexer(echoInput{r: r})
+}
+
+// NewEchoFromBytes returns a lexer scanning b directly.
+// Bytes(), Text(), YYText and Text of tokens share the memory with b, and these strings outlive lexing.
+// b must not be modified while the lexer or any text returned by it is in use.
+func NewEchoFromBytes(b []byte) *echoLexer {
+	return echoNewLexer(echoInput{buf: b, err: io.EOF})
+}
+
+// NewEchoFromString returns a lexer scanning s without copying it.
+func NewEchoFromString(s string) *echoLexer {
+	return echoNewLexer(echoInput{buf: echoStringBytes(s), err: io.EOF})
+}
+
+func echoNewLexer(in echoInput) *echoLexer {
+	in.position = echoStartPosition
+	return &echoLexer{
+		in:          in,
+		beginPos:    0,
+		finPos:      0,
+		currPos:     0,
+		finRegexID:  0,
+		currStateID: echoStartStates[0],
+		start:       in.position,
+		end:         in.position,
+		cond:        0,
+		Out:         os.Stdout,
+	}
+}
+
+// echoStringBytes returns the bytes of s without copying. They must not be modified.
+func echoStringBytes(s string) []byte {
+	return *(*[]byte)(unsafe.Pointer(&struct {
+		string
+		int
+	}{s, len(s)}))
+}
+
+// Bytes returns the text of the current token.
+// It is a sub-slice of the input buffer and valid until the next call of Next.
+// PeekRune, ReadRune, ReadUntil and Consume may refill the buffer and invalidate it as well.
+func (yylex *echoLexer) Bytes() []byte {
+	return yylex.text
+}
+
+// Pos returns the position of the beginning of the current token.
+func (yylex *echoLexer) Pos() EchoPosition {
+	return yylex.start
+}
+
+// EndPos returns the position just after the current token.
+func (yylex *echoLexer) EndPos() EchoPosition {
+	return yylex.end
+}
+
+// Text returns the text of the current token. It is the same as YYText.
+func (yylex *echoLexer) Text() string {
+	return yylex.YYText
+}
+
+// textString returns b as a string.
+// It shares the memory with an in-memory input, which the caller keeps unchanged while the text is in use.
+func (yylex *echoLexer) textString(b []byte) string {
+	if yylex.in.r == nil {
+		return *(*string)(unsafe.Pointer(&b))
+	}
+
+	return string(b)
+}
+
+// fill reads more bytes from the input after discarding bytes before the current token.
+func (yylex *echoLexer) fill() error {
+	in := &yylex.in
+	if in.err != nil {
+		return in.err
+	}
+	if keep := yylex.textPos; keep > 0 {
+		n := copy(in.buf, in.buf[keep:])
+		in.buf = in.buf[:n]
+		yylex.textPos = 0
+		yylex.beginPos -= keep
+		yylex.finPos -= keep
+		yylex.currPos -= keep
+	}
+	if len(in.buf) == cap(in.buf) {
+		buf := make([]byte, len(in.buf), 2*cap(in.buf)+echoBufSize)
+		copy(buf, in.buf)
+		in.buf = buf
+	}
+	for i := 0; i < 100; i++ {
+		n, err := in.r.Read(in.buf[len(in.buf):cap(in.buf)])
+		in.buf = in.buf[:len(in.buf)+n]
+		if err != nil {
+			in.err = err
+		}
+		if n > 0 {
+			return nil
+		} else if err != nil {
+			return err
+		}
+	}
+	in.err = io.ErrNoProgress
+
+	return in.err
+}
+
+// currRune returns the rune at the read position without consuming it.
+// An incomplete UTF-8 sequence at the end of the input is utf8.RuneError of size 1.
+func (yylex *echoLexer) currRune() (rune, int, error) {
+	for !utf8.FullRune(yylex.in.buf[yylex.currPos:]) {
+		if err := yylex.fill(); err != nil {
+			if yylex.currPos < len(yylex.in.buf) {
+				break
+			}
+			return 0, 0, err
+		}
+	}
+	ru, size := utf8.DecodeRune(yylex.in.buf[yylex.currPos:])
+
+	return ru, size, nil
+}
+
+// scan runs the DFA from the current state while transitions exist.
+// It returns false if the input is exhausted at the beginning of a token.
+func (yylex *echoLexer) scan() (bool, error) {
+	for {
+		yyr, yysize, err := yylex.currRune()
+		if err != nil {
+			return yylex.scanEnd(err)
+		}
+		yyNxStID := echoNextStep(yylex.currStateID, yyr)
+		if yyNxStID == 0 {
+			return true, nil
+		}
+		yylex.currStateID = yyNxStID
+		yylex.currPos += yysize
+		if echoIsFinState(yyNxStID) {
+			yylex.finPos = yylex.currPos
+			yylex.finRegexID = echoStateIDToRegexID[yyNxStID]
+		}
+	}
+}
+
+// scanEnd returns the result of scan when reading a rune fails by err.
+func (yylex *echoLexer) scanEnd(err error) (bool, error) {
+	if !errors.Is(err, io.EOF) {
+		return false, err
+	}
+
+	return yylex.currPos != yylex.beginPos, nil
+}
+
+func (yylex *echoLexer) Next() (EchoTokenKind, error) {
+yystart:
+	for {
+		if !yylex.more {
+			yylex.textPos = yylex.beginPos
+		}
+		yymatched, err := yylex.scan()
+		if err != nil {
+			return 0, err
+		} else if !yymatched {
+			// input is exhausted
+			break
+		}
+		if yylex.finRegexID == 0 {
+			// no rule matched, and the first rune is consumed. the text appended by More is dropped.
+			yylex.more = false
+			_, yysize := utf8.DecodeRune(yylex.in.buf[yylex.beginPos:])
+			yylex.finPos = yylex.beginPos + yysize
+		}
+		if !yylex.more {
+			yylex.textPos = yylex.beginPos
+			yylex.start = yylex.in.position
+			yylex.startCR = yylex.in.cr
+		}
+		yylex.more = false
+		yylex.text = yylex.in.buf[yylex.textPos:yylex.finPos]
+		yylex.YYText = yylex.textString(yylex.text)
+		yylex.in.advance(yylex.in.buf[yylex.beginPos:yylex.finPos])
+		yylex.end = yylex.in.position
+		yylex.beginPos = yylex.finPos
+		yylex.currPos = yylex.finPos
+		yylex.currStateID = echoStartStates[yylex.cond]
+
+		regexID := yylex.finRegexID
+		yylex.finRegexID = 0
+		switch regexID {
+		case 0:
+			if err := yylex.Echo(); err != nil {
+				return 0, err
+			}
+		case 1:
+//line echo.l:5
+			return Digits, nil
+//line echo.go:445
+
+		default:
+			return 0, ErrEchoScan
+		}
+	}
+
+	yylex.more = false
+	yylex.text = nil
+	yylex.YYText = ""
+	yylex.start = yylex.in.position
+	yylex.end = yylex.in.position
+	if yylex.PopInput() {
+		goto yystart
+	}
+
+	return 0, io.EOF
+}
+
+// Echo writes the current token to Out. It corresponds to ECHO of lex.
+// Runes no rule matches are written by Echo.
+func (yylex *echoLexer) Echo() error {
+	_, err := yylex.Out.Write(yylex.text)
+	return err
+}
+
+// NextToken is the same as Next, but returns the token with its text and positions.
+// With ErrEchoScan the token has the text and positions of the rune no rule matched,
+// and with io.EOF it has the position of the end of the input.
+func (yylex *echoLexer) NextToken() (EchoToken, error) {
+	kind, err := yylex.Next()
+	return EchoToken{
+		Kind:  kind,
+		Text:  yylex.YYText,
+		Start: yylex.start,
+		End:   yylex.end,
+	}, err
+}
+
+// Tokens lexes the rest of the input and returns the tokens.
+// It stops at the first error other than io.EOF and returns the tokens before it with the error.
+func (yylex *echoLexer) Tokens() ([]EchoToken, error) {
+	toks := make([]EchoToken, 0)
+	for {
+		tok, err := yylex.NextToken()
+		if errors.Is(err, io.EOF) {
+			return toks, nil
+		} else if err != nil {
+			return toks, err
+		}
+		toks = append(toks, tok)
+	}
+}
+
+// Each calls yield with the rest of the tokens in order until yield returns false.
+// An error other than io.EOF is passed to yield and ends the iteration.
+// When yield stops the iteration, the lexer is just after the last token passed to yield,
+// so lexing can be resumed by Next, NextToken or Each.
+// It is the fallback of All for Go older than 1.23.
+func (yylex *echoLexer) Each(yield func(EchoToken, error) bool) {
+	for {
+		tok, err := yylex.NextToken()
+		if errors.Is(err, io.EOF) {
+			return
+		}
+		if !yield(tok, err) || err != nil {
+			return
+		}
+	}
+}
+
+// Less pushes back the current token except its first n bytes, and they are scanned again.
+// It corresponds to yyless of lex. n must be between 0 and len(Bytes()), and
+// it returns ErrEchoNegativeCount or ErrEchoLongCount without changing anything otherwise.
+// Less(0) without changing the start condition makes an infinite loop.
+func (yylex *echoLexer) Less(n int) error {
+	if n < 0 {
+		return ErrEchoNegativeCount
+	} else if n > len(yylex.text) {
+		return ErrEchoLongCount
+	}
+	yylex.text = yylex.text[:n]
+	yylex.YYText = yylex.YYText[:n]
+	yylex.beginPos = yylex.textPos + n
+	yylex.finPos = yylex.beginPos
+	yylex.currPos = yylex.beginPos
+	yylex.in.position = yylex.start
+	yylex.in.cr = yylex.startCR
+	yylex.in.advance(yylex.text)
+	yylex.end = yylex.in.position
+
+	return nil
+}
+
+// PeekRune returns the next rune of the input without consuming it.
+// It returns io.EOF at the end of the input.
+func (yylex *echoLexer) PeekRune() (rune, error) {
+	r, _, err := yylex.currRune()
+	// reading may move the buffer
+	yylex.text = yylex.in.buf[yylex.textPos:yylex.beginPos]
+
+	return r, err
+}
+
+// ReadRune reads the next rune of the input and appends it to the current token.
+// It returns io.ErrUnexpectedEOF at the end of the input because the current token is unterminated,
+// so returning the error from the action does not end lexing silently.
+func (yylex *echoLexer) ReadRune() (rune, int, error) {
+	r, size, err := yylex.currRune()
+	if err != nil {
+		yylex.text = yylex.in.buf[yylex.textPos:yylex.beginPos]
+		if errors.Is(err, io.EOF) {
+			err = io.ErrUnexpectedEOF
+		}
+		return 0, 0, err
+	}
+	yylex.extend(size)
+
+	return r, size, nil
+}
+
+// ReadUntil reads the input until the first occurrence of delim and appends it to the current token.
+// It returns the read text including delim.
+// If the input ends before delim, it reads the rest and returns io.ErrUnexpectedEOF.
+func (yylex *echoLexer) ReadUntil(delim string) (string, error) {
+	d := echoStringBytes(delim)
+	searched := 0 // number of bytes from the read position which do not begin delim
+	for {
+		rest := yylex.in.buf[yylex.beginPos:]
+		if i := bytes.Index(rest[searched:], d); i >= 0 {
+			n := searched + i + len(d)
+			s := yylex.textString(rest[:n])
+			yylex.extend(n)
+			return s, nil
+		}
+		if len(rest) >= len(d) {
+			searched = len(rest) - len(d) + 1
+		}
+		if err := yylex.fill(); err != nil {
+			s := yylex.textString(yylex.in.buf[yylex.beginPos:])
+			yylex.extend(len(yylex.in.buf) - yylex.beginPos)
+			if errors.Is(err, io.EOF) {
+				err = io.ErrUnexpectedEOF
+			}
+			return s, err
+		}
+	}
+}
+
+// Consume reads n bytes of the input and appends them to the current token.
+// If the input ends before n bytes, it reads the rest and returns io.ErrUnexpectedEOF.
+// It returns the number of bytes read, and ErrEchoNegativeCount if n is negative.
+func (yylex *echoLexer) Consume(n int) (int, error) {
+	if n < 0 {
+		return 0, ErrEchoNegativeCount
+	}
+	for len(yylex.in.buf)-yylex.beginPos < n {
+		if err := yylex.fill(); err != nil {
+			m := len(yylex.in.buf) - yylex.beginPos
+			yylex.extend(m)
+			if errors.Is(err, io.EOF) {
+				err = io.ErrUnexpectedEOF
+			}
+			return m, err
+		}
+	}
+	yylex.extend(n)
+
+	return n, nil
+}
+
+// extend appends n bytes at the read position to the current token.
+func (yylex *echoLexer) extend(n int) {
+	yylex.in.advance(yylex.in.buf[yylex.beginPos : yylex.beginPos+n])
+	yylex.beginPos += n
+	yylex.finPos = yylex.beginPos
+	yylex.currPos = yylex.beginPos
+	yylex.text = yylex.in.buf[yylex.textPos:yylex.beginPos]
+	yylex.YYText = yylex.textString(yylex.text)
+	yylex.end = yylex.in.position
+}
+
+// More makes the next token appended to the current text instead of replacing it.
+// It corresponds to yymore of lex. Pos() of the next token is the beginning of the current one.
+// The current text is dropped if the next runes match no rule or the input is switched.
+func (yylex *echoLexer) More() {
+	yylex.more = true
+}
+
+// Unput pushes s back to the input, and it is scanned before the rest of the input.
+// It corresponds to unput of lex. Positions count s as a part of the input.
+// The input is never modified; the current text, s and the buffered bytes are copied to a new buffer.
+// The rest of an in-memory input is read through a reader afterwards instead of being copied,
+// so texts of the following tokens do not share the memory with the input.
+func (yylex *echoLexer) Unput(s string) {
+	in := &yylex.in
+	n := len(yylex.text)
+	rest := in.buf[yylex.beginPos:]
+	if in.r == nil {
+		in.r = bytes.NewReader(rest)
+		in.err = nil
+		rest = nil
+	}
+	buf := make([]byte, 0, n+len(s)+len(rest)+echoBufSize)
+	buf = append(buf, in.buf[yylex.textPos:yylex.beginPos]...)
+	buf = append(buf, s...)
+	buf = append(buf, rest...)
+	in.buf = buf
+	yylex.text = buf[:n]
+	yylex.textPos = 0
+	yylex.beginPos = n
+	yylex.finPos = n
+	yylex.currPos = n
+}
+
+// Begin switches the start condition. It corresponds to BEGIN of lex.
+func (yylex *echoLexer) Begin(cond int) {
+	yylex.cond = cond
+	yylex.currStateID = echoStartStates[cond]
+}
+
+// StartCondition returns the current start condition.
+func (yylex *echoLexer) StartCondition() int {
+	return yylex.cond
+}
+
+// PushState saves the current start condition on the stack and switches to cond.
+// It corresponds to yy_push_state of flex.
+func (yylex *echoLexer) PushState(cond int) {
+	yylex.condStack = append(yylex.condStack, yylex.cond)
+	yylex.Begin(cond)
+}
+
+// PopState switches to the start condition on the top of the stack and removes it.
+// It returns ErrEchoEmptyStack if the stack is empty.
+func (yylex *echoLexer) PopState() error {
+	n := len(yylex.condStack)
+	if n == 0 {
+		return ErrEchoEmptyStack
+	}
+	cond := yylex.condStack[n-1]
+	yylex.condStack = yylex.condStack[:n-1]
+	yylex.Begin(cond)
+
+	return nil
+}
+
+// TopState returns the start condition on the top of the stack.
+// It returns ErrEchoEmptyStack if the stack is empty.
+func (yylex *echoLexer) TopState() (int, error) {
+	n := len(yylex.condStack)
+	if n == 0 {
+		return 0, ErrEchoEmptyStack
+	}
+
+	return yylex.condStack[n-1], nil
+}
+
+// StateStack returns a copy of the start condition stack. The last element is the top.
+func (yylex *echoLexer) StateStack() []int {
+	return append([]int{}, yylex.condStack...)
+}
+
+// PushInput suspends the current input and starts reading r.
+// The suspended input is resumed when r is exhausted or PopInput is called.
+func (yylex *echoLexer) PushInput(r io.Reader) {
+	in := yylex.in
+	in.pos = yylex.currPos
+	yylex.inputStack = append(yylex.inputStack, in)
+	yylex.in = echoInput{r: r, position: echoStartPosition}
+	yylex.textPos = 0
+	yylex.more = false
+	yylex.beginPos = 0
+	yylex.finPos = 0
+	yylex.currPos = 0
+	yylex.finRegexID = 0
+	yylex.currStateID = echoStartStates[yylex.cond]
+	yylex.inputID++
+}
+
+// PopInput discards the current input and resumes the last suspended one.
+// It returns false if there is no suspended input.
+func (yylex *echoLexer) PopInput() bool {
+	n := len(yylex.inputStack)
+	if n == 0 {
+		return false
+	}
+	yylex.in = yylex.inputStack[n-1]
+	yylex.inputStack = yylex.inputStack[:n-1]
+	yylex.textPos = yylex.in.pos
+	yylex.more = false
+	yylex.beginPos = yylex.in.pos
+	yylex.finPos = yylex.in.pos
+	yylex.currPos = yylex.in.pos
+	yylex.finRegexID = 0
+	yylex.currStateID = echoStartStates[yylex.cond]
+	yylex.inputID++
+
+	return true
+}
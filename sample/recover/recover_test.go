@@ -0,0 +1,85 @@
+package recover
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const input = "foo = 1 + 2;\nbar ?? baz;\nqux"
+
+func TestSync(t *testing.T) {
+	lex := NewSync(strings.NewReader(input))
+	toks, err := lex.Tokens()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	texts := make([]string, 0, len(toks))
+	for _, tok := range toks {
+		texts = append(texts, tok.Text)
+	}
+	expected := []string{"foo", ";", "bar", ";", "qux"}
+	if !reflect.DeepEqual(texts, expected) {
+		t.Errorf("expected %q but %q", expected, texts)
+	}
+	if last := toks[len(toks)-1]; last.Start.Line != 3 || last.Start.Column != 1 {
+		t.Errorf("expected qux at 3:1 but %v", last.Start)
+	}
+
+	if len(lex.Errors) != 2 {
+		t.Fatalf("expected 2 errors but %v", lex.Errors)
+	}
+	for _, err := range lex.Errors {
+		if !errors.Is(err, ErrSyncScan) {
+			t.Errorf("expected ErrSyncScan but %v", err)
+		}
+	}
+}
+
+func TestSync_OnError(t *testing.T) {
+	lex := NewSyncFromString(input)
+	skipped := make([]string, 0)
+	lex.OnError = func(err error) error {
+		skipped = append(skipped, lex.Text())
+		if lex.Text() == "?? baz" {
+			return fmt.Errorf("recovered: %w", err)
+		}
+		return nil
+	}
+	if _, err := lex.Tokens(); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []string{"= 1 + 2", "?? baz"}
+	if !reflect.DeepEqual(skipped, expected) {
+		t.Errorf("expected %q but %q", expected, skipped)
+	}
+	if len(lex.Errors) != 1 || lex.Errors[0].Error() != `recovered: 2:5: failed to scan "?? baz"` {
+		t.Errorf("expected the error of 2:5 but %v", lex.Errors)
+	}
+	var scanErr *SyncScanError
+	if !errors.As(lex.Errors[0], &scanErr) || scanErr.Partial != "" || scanErr.Rune != '?' {
+		t.Errorf("expected SyncScanError of ? but %#v", lex.Errors[0])
+	}
+}
+
+func TestEcho(t *testing.T) {
+	var out bytes.Buffer
+	lex := NewEcho(strings.NewReader("a12b 3\n"))
+	lex.Out = &out
+	toks, err := lex.Tokens()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(toks) != 2 || toks[0].Text != "12" || toks[1].Text != "3" {
+		t.Errorf("expected 12 and 3 but %+v", toks)
+	}
+	if out.String() != "ab \n" {
+		t.Errorf("expected unmatched runes %q but %q", "ab \n", out.String())
+	}
+}
@@ -0,0 +1,813 @@
+// Code generated by tlex. DO NOT EDIT.
+
+package recover
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"unicode/utf8"
+	"unsafe"
+)
+
+type SyncTokenKind int
+
+const (
+	Ident SyncTokenKind = iota + 1
+	Number
+	Semicolon
+)
+
+var syncTokenKindNames = [...]string{
+	0:         "",
+	Ident:     "Ident",
+	Number:    "Number",
+	Semicolon: "Semicolon",
+}
+
+func (k SyncTokenKind) String() string {
+	if 0 < k && int(k) < len(syncTokenKindNames) {
+		return syncTokenKindNames[k]
+	}
+	return fmt.Sprintf("SyncTokenKind(%d)", int(k))
+}
+
+// SyncTokenKindByName maps a token name to its kind.
+var SyncTokenKindByName = map[string]SyncTokenKind{
+	"Ident":     Ident,
+	"Number":    Number,
+	"Semicolon": Semicolon,
+}
+
+// names of start conditions
+var syncConditionNames = [...]string{
+	"INITIAL",
+}
+
+type syncStateID = int
+type syncRegexID = int
+
+var (
+	ErrSyncScan          = errors.New("failed to scan")
+	ErrSyncEmptyStack    = errors.New("start condition stack is empty")
+	ErrSyncNegativeCount = errors.New("negative count")
+	ErrSyncLongCount     = errors.New("count exceeds the current token")
+)
+
+// syncSyncRunes is the synchronizing runes. Runes no rule matches are skipped until one of them.
+const syncSyncRunes = ";\n"
+
+// start state of each start condition
+var syncStartStates = []syncStateID{
+	1,
+}
+
+// state id to regex id
+var syncStateIDToRegexID = []syncRegexID{
+	0, // state 0 is dead state
+	9223372036854775807,
+	4,
+	2,
+	3,
+	1,
+}
+
+var syncFinStates = []bool{
+	false,
+	false,
+	true,
+	true,
+	true,
+	true,
+}
+
+// class of each rune less than 256. class 0 has no transition.
+var syncByteClasses = [256]int32{
+	0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 1, 0, 0, 0, 0, 0,
+	0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0,
+	1, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0,
+	2, 2, 2, 2, 2, 2, 2, 2, 2, 2, 0, 3, 0, 0, 0, 0,
+	0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0,
+	0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0,
+	0, 4, 4, 4, 4, 4, 4, 4, 4, 4, 4, 4, 4, 4, 4, 4,
+	4, 4, 4, 4, 4, 4, 4, 4, 4, 4, 4, 0, 0, 0, 0, 0,
+	0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0,
+	0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0,
+	0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0,
+	0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0,
+	0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0,
+	0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0,
+	0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0,
+	0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0,
+}
+
+// syncclassRange is the class of runes in [l, r].
+type syncclassRange struct {
+	l, r  int32
+	class int32
+}
+
+// classes of runes not less than 256 in ascending order of runes
+var syncClassRanges = []syncclassRange{}
+
+const syncNumClasses = 5
+
+// syncTransitions[s*syncNumClasses+c] is the next state of state s by a rune of class c.
+var syncTransitions = []syncStateID{
+	0, 0, 0, 0, 0, // state 0 is dead state
+	0, 2, 3, 4, 5,
+	0, 0, 0, 0, 0,
+	0, 0, 3, 0, 0,
+	0, 0, 0, 0, 0,
+	0, 0, 0, 0, 5,
+}
+
+func syncClassOf(r rune) int32 {
+	if 0 <= r && r < 256 {
+		return syncByteClasses[r]
+	}
+	i, j := 0, len(syncClassRanges)
+	for i < j {
+		h := int(uint(i+j) >> 1)
+		if syncClassRanges[h].r < r {
+			i = h + 1
+		} else {
+			j = h
+		}
+	}
+	if i < len(syncClassRanges) && syncClassRanges[i].l <= r {
+		return syncClassRanges[i].class
+	}
+
+	return 0
+}
+
+func syncNextStep(id syncStateID, r rune) syncStateID {
+	return syncTransitions[id*syncNumClasses+int(syncClassOf(r))]
+}
+
+func syncIsFinState(id syncStateID) bool {
+	return syncFinStates[id]
+}
+
+type syncLexer struct {
+	in          syncInput
+	beginPos    int // position of the current token in in.buf
+	finPos      int // end position of the longest match in in.buf
+	currPos     int // read position in in.buf
+	finRegexID  int
+	currStateID syncStateID
+	cond        int
+	condStack   []int
+	inputStack  []syncInput
+	inputID     int
+	textPos     int  // position of the text of the current token in in.buf
+	more        bool // the next token is appended to the current text
+	text        []byte
+	start       SyncPosition // position of the current token
+	startCR     bool         // in.cr at start
+	end         SyncPosition // position just after the current token
+	YYText      string
+	// OnError is called with a scan error after the runes no rule matches are skipped.
+	// Lexing continues if it returns nil, and the returned error is reported otherwise.
+	OnError func(err error) error
+	Errors  []error // scan errors collected instead of being returned by Next
+}
+
+// syncBufSize is the initial size of the input buffer.
+// The buffer grows when a token does not fit in it.
+const syncBufSize = 4096
+
+// SyncPosition is a position in the input.
+// A line ends with LF, CR or CRLF, and CRLF is one line break.
+type SyncPosition struct {
+	Offset     int // byte offset, starting at 0
+	Line       int // line number, starting at 1
+	Column     int // column in runes, starting at 1
+	ByteColumn int // column in bytes, starting at 1
+}
+
+// syncStartPosition is the position of the beginning of an input.
+var syncStartPosition = SyncPosition{Line: 1, Column: 1, ByteColumn: 1}
+
+func (p SyncPosition) String() string {
+	return fmt.Sprintf("%d:%d", p.Line, p.Column)
+}
+
+// SyncToken is a token returned by NextToken.
+// Unlike YYText, Text is not overwritten by later tokens.
+// With NewFromBytes, Text shares the memory with the input as YYText.
+type SyncToken struct {
+	Kind  SyncTokenKind
+	Text  string
+	Start SyncPosition // position of the beginning of the token
+	End   SyncPosition // position just after the token
+}
+
+// SyncScanError is the error of runes no rule matches.
+// It wraps ErrSyncScan.
+type SyncScanError struct {
+	Pos       SyncPosition // position of the skipped runes
+	Text      string       // skipped runes
+	Partial   string       // text read from Pos before the lexer got stuck, which no rule matches
+	Rune      rune         // rune no rule can continue with after Partial, or -1 at the end of the input
+	Condition int          // start condition
+}
+
+func (e *SyncScanError) Error() string {
+	msg := fmt.Sprintf("%v: %v %q", e.Pos, ErrSyncScan, e.Text)
+	if e.Condition != 0 {
+		msg += " in start condition " + syncConditionNames[e.Condition]
+	}
+
+	return msg
+}
+
+func (e *SyncScanError) Unwrap() error {
+	return ErrSyncScan
+}
+
+// syncInput is an input and the buffer of bytes read from it.
+// Bytes before the current token are discarded when the buffer is refilled,
+// so the buffer size is bounded by the longest token.
+// An in-memory input has no reader and its buffer is the whole input, which is never modified.
+type syncInput struct {
+	r   io.Reader
+	buf []byte
+	err error // error of the last read, which is reported after buf is consumed
+	pos int   // read position of a suspended input
+
+	position SyncPosition // position of the beginning of the current token
+	cr       bool         // the last byte before position is CR
+}
+
+// advance moves the position over b.
+func (in *syncInput) advance(b []byte) {
+	p := &in.position
+	p.Offset += len(b)
+	for _, c := range b {
+		switch {
+		case c == '\n' && in.cr:
+			// LF of CRLF. the line break is counted at CR.
+		case c == '\n' || c == '\r':
+			p.Line++
+			p.Column = 1
+			p.ByteColumn = 1
+		default:
+			p.ByteColumn++
+			if utf8.RuneStart(c) {
+				p.Column++
+			}
+		}
+		in.cr = c == '\r'
+	}
+}
+
+func NewSync(r io.Reader) *syncLexer {
+	return syncNewLexer(syncInput{r: r})
+}
+
+// NewSyncFromBytes returns a lexer scanning b directly.
+// Bytes(), Text(), YYText and Text of tokens share the memory with b, and these strings outlive lexing.
+// b must not be modified while the lexer or any text returned by it is in use.
+func NewSyncFromBytes(b []byte) *syncLexer {
+	return syncNewLexer(syncInput{buf: b, err: io.EOF})
+}
+
+// NewSyncFromString returns a lexer scanning s without copying it.
+func NewSyncFromString(s string) *syncLexer {
+	return syncNewLexer(syncInput{buf: syncStringBytes(s), err: io.EOF})
+}
+
+func syncNewLexer(in syncInput) *syncLexer {
+	in.position = syncStartPosition
+	return &syncLexer{
+		in:          in,
+		beginPos:    0,
+		finPos:      0,
+		currPos:     0,
+		finRegexID:  0,
+		currStateID: syncStartStates[0],
+		start:       in.position,
+		end:         in.position,
+		cond:        0,
+	}
+}
+
+// syncStringBytes returns the bytes of s without copying. They must not be modified.
+func syncStringBytes(s string) []byte {
+	return *(*[]byte)(unsafe.Pointer(&struct {
+		string
+		int
+	}{s, len(s)}))
+}
+
+// Bytes returns the text of the current token.
+// It is a sub-slice of the input buffer and valid until the next call of Next.
+// PeekRune, ReadRune, ReadUntil and Consume may refill the buffer and invalidate it as well.
+func (yylex *syncLexer) Bytes() []byte {
+	return yylex.text
+}
+
+// Pos returns the position of the beginning of the current token.
+func (yylex *syncLexer) Pos() SyncPosition {
+	return yylex.start
+}
+
+// EndPos returns the position just after the current token.
+func (yylex *syncLexer) EndPos() SyncPosition {
+	return yylex.end
+}
+
+// Text returns the text of the current token. It is the same as YYText.
+func (yylex *syncLexer) Text() string {
+	return yylex.YYText
+}
+
+// textString returns b as a string.
+// It shares the memory with an in-memory input, which the caller keeps unchanged while the text is in use.
+func (yylex *syncLexer) textString(b []byte) string {
+	if yylex.in.r == nil {
+		return *(*string)(unsafe.Pointer(&b))
+	}
+
+	return string(b)
+}
+
+// fill reads more bytes from the input after discarding bytes before the current token.
+func (yylex *syncLexer) fill() error {
+	in := &yylex.in
+	if in.err != nil {
+		return in.err
+	}
+	if keep := yylex.textPos; keep > 0 {
+		n := copy(in.buf, in.buf[keep:])
+		in.buf = in.buf[:n]
+		yylex.textPos = 0
+		yylex.beginPos -= keep
+		yylex.finPos -= keep
+		yylex.currPos -= keep
+	}
+	if len(in.buf) == cap(in.buf) {
+		buf := make([]byte, len(in.buf), 2*cap(in.buf)+syncBufSize)
+		copy(buf, in.buf)
+		in.buf = buf
+	}
+	for i := 0; i < 100; i++ {
+		n, err := in.r.Read(in.buf[len(in.buf):cap(in.buf)])
+		in.buf = in.buf[:len(in.buf)+n]
+		if err != nil {
+			in.err = err
+		}
+		if n > 0 {
+			return nil
+		} else if err != nil {
+			return err
+		}
+	}
+	in.err = io.ErrNoProgress
+
+	return in.err
+}
+
+// currRune returns the rune at the read position without consuming it.
+// An incomplete UTF-8 sequence at the end of the input is utf8.RuneError of size 1.
+func (yylex *syncLexer) currRune() (rune, int, error) {
+	for !utf8.FullRune(yylex.in.buf[yylex.currPos:]) {
+		if err := yylex.fill(); err != nil {
+			if yylex.currPos < len(yylex.in.buf) {
+				break
+			}
+			return 0, 0, err
+		}
+	}
+	ru, size := utf8.DecodeRune(yylex.in.buf[yylex.currPos:])
+
+	return ru, size, nil
+}
+
+// scan runs the DFA from the current state while transitions exist.
+// It returns false if the input is exhausted at the beginning of a token.
+func (yylex *syncLexer) scan() (bool, error) {
+	for {
+		yyr, yysize, err := yylex.currRune()
+		if err != nil {
+			return yylex.scanEnd(err)
+		}
+		yyNxStID := syncNextStep(yylex.currStateID, yyr)
+		if yyNxStID == 0 {
+			return true, nil
+		}
+		yylex.currStateID = yyNxStID
+		yylex.currPos += yysize
+		if syncIsFinState(yyNxStID) {
+			yylex.finPos = yylex.currPos
+			yylex.finRegexID = syncStateIDToRegexID[yyNxStID]
+		}
+	}
+}
+
+// scanEnd returns the result of scan when reading a rune fails by err.
+func (yylex *syncLexer) scanEnd(err error) (bool, error) {
+	if !errors.Is(err, io.EOF) {
+		return false, err
+	}
+
+	return yylex.currPos != yylex.beginPos, nil
+}
+
+func (yylex *syncLexer) Next() (SyncTokenKind, error) {
+yystart:
+	for {
+		if !yylex.more {
+			yylex.textPos = yylex.beginPos
+		}
+		yymatched, err := yylex.scan()
+		if err != nil {
+			return 0, err
+		} else if !yymatched {
+			// input is exhausted
+			break
+		}
+		var yyerr *SyncScanError
+		if yylex.finRegexID == 0 {
+			// no rule matched, and the first rune is consumed. the text appended by More is dropped.
+			yylex.more = false
+			yyerr = yylex.scanError()
+			_, yysize := utf8.DecodeRune(yylex.in.buf[yylex.beginPos:])
+			yylex.finPos = yylex.beginPos + yysize
+			yylex.skipToSync()
+		}
+		if !yylex.more {
+			yylex.textPos = yylex.beginPos
+			yylex.start = yylex.in.position
+			yylex.startCR = yylex.in.cr
+		}
+		yylex.more = false
+		yylex.text = yylex.in.buf[yylex.textPos:yylex.finPos]
+		yylex.YYText = yylex.textString(yylex.text)
+		yylex.in.advance(yylex.in.buf[yylex.beginPos:yylex.finPos])
+		yylex.end = yylex.in.position
+		yylex.beginPos = yylex.finPos
+		yylex.currPos = yylex.finPos
+		yylex.currStateID = syncStartStates[yylex.cond]
+
+		regexID := yylex.finRegexID
+		yylex.finRegexID = 0
+		switch regexID {
+		case 0:
+			yyerr.Text = yylex.YYText
+			if err := yylex.recoverError(yyerr); err != nil {
+				return 0, err
+			}
+		case 1:
+//line sync.l:5
+			return Ident, nil
+//line sync.go:469
+		case 2:
+//line sync.l:6
+			return Number, nil
+//line sync.go:473
+		case 3:
+//line sync.l:7
+			return Semicolon, nil
+//line sync.go:477
+		case 4:
+			goto yystart
+
+		default:
+			return 0, ErrSyncScan
+		}
+	}
+
+	yylex.more = false
+	yylex.text = nil
+	yylex.YYText = ""
+	yylex.start = yylex.in.position
+	yylex.end = yylex.in.position
+	if yylex.PopInput() {
+		goto yystart
+	}
+
+	return 0, io.EOF
+}
+
+// skipToSync extends the runes no rule matches to just before the next synchronizing rune.
+// A read error stops skipping, and it is returned by the next scan.
+func (yylex *syncLexer) skipToSync() {
+	yylex.currPos = yylex.finPos
+	for {
+		r, size, err := yylex.currRune()
+		if err != nil || strings.ContainsRune(syncSyncRunes, r) {
+			break
+		}
+		yylex.currPos += size
+	}
+	yylex.finPos = yylex.currPos
+}
+
+// scanError returns the error of runes no rule matches when the lexer gets stuck.
+// It must be called before the runes are skipped.
+func (yylex *syncLexer) scanError() *SyncScanError {
+	e := &SyncScanError{
+		Pos:       yylex.in.position,
+		Partial:   string(yylex.in.buf[yylex.beginPos:yylex.currPos]),
+		Rune:      -1,
+		Condition: yylex.cond,
+	}
+	if yylex.currPos < len(yylex.in.buf) {
+		e.Rune, _ = utf8.DecodeRune(yylex.in.buf[yylex.currPos:])
+	}
+
+	return e
+}
+
+// recoverError handles the scan error err after the runes no rule matches are skipped.
+// It returns nil if lexing continues.
+func (yylex *syncLexer) recoverError(err error) error {
+	if yylex.OnError != nil {
+		if err = yylex.OnError(err); err == nil {
+			return nil
+		}
+	}
+	yylex.Errors = append(yylex.Errors, err)
+
+	return nil
+}
+
+// NextToken is the same as Next, but returns the token with its text and positions.
+// With ErrSyncScan the token has the text and positions of the rune no rule matched,
+// and with io.EOF it has the position of the end of the input.
+func (yylex *syncLexer) NextToken() (SyncToken, error) {
+	kind, err := yylex.Next()
+	return SyncToken{
+		Kind:  kind,
+		Text:  yylex.YYText,
+		Start: yylex.start,
+		End:   yylex.end,
+	}, err
+}
+
+// Tokens lexes the rest of the input and returns the tokens.
+// It stops at the first error other than io.EOF and returns the tokens before it with the error.
+func (yylex *syncLexer) Tokens() ([]SyncToken, error) {
+	toks := make([]SyncToken, 0)
+	for {
+		tok, err := yylex.NextToken()
+		if errors.Is(err, io.EOF) {
+			return toks, nil
+		} else if err != nil {
+			return toks, err
+		}
+		toks = append(toks, tok)
+	}
+}
+
+// Each calls yield with the rest of the tokens in order until yield returns false.
+// An error other than io.EOF is passed to yield and ends the iteration.
+// When yield stops the iteration, the lexer is just after the last token passed to yield,
+// so lexing can be resumed by Next, NextToken or Each.
+// It is the fallback of All for Go older than 1.23.
+func (yylex *syncLexer) Each(yield func(SyncToken, error) bool) {
+	for {
+		tok, err := yylex.NextToken()
+		if errors.Is(err, io.EOF) {
+			return
+		}
+		if !yield(tok, err) || err != nil {
+			return
+		}
+	}
+}
+
+// Less pushes back the current token except its first n bytes, and they are scanned again.
+// It corresponds to yyless of lex. n must be between 0 and len(Bytes()), and
+// it returns ErrSyncNegativeCount or ErrSyncLongCount without changing anything otherwise.
+// Less(0) without changing the start condition makes an infinite loop.
+func (yylex *syncLexer) Less(n int) error {
+	if n < 0 {
+		return ErrSyncNegativeCount
+	} else if n > len(yylex.text) {
+		return ErrSyncLongCount
+	}
+	yylex.text = yylex.text[:n]
+	yylex.YYText = yylex.YYText[:n]
+	yylex.beginPos = yylex.textPos + n
+	yylex.finPos = yylex.beginPos
+	yylex.currPos = yylex.beginPos
+	yylex.in.position = yylex.start
+	yylex.in.cr = yylex.startCR
+	yylex.in.advance(yylex.text)
+	yylex.end = yylex.in.position
+
+	return nil
+}
+
+// PeekRune returns the next rune of the input without consuming it.
+// It returns io.EOF at the end of the input.
+func (yylex *syncLexer) PeekRune() (rune, error) {
+	r, _, err := yylex.currRune()
+	// reading may move the buffer
+	yylex.text = yylex.in.buf[yylex.textPos:yylex.beginPos]
+
+	return r, err
+}
+
+// ReadRune reads the next rune of the input and appends it to the current token.
+// It returns io.ErrUnexpectedEOF at the end of the input because the current token is unterminated,
+// so returning the error from the action does not end lexing silently.
+func (yylex *syncLexer) ReadRune() (rune, int, error) {
+	r, size, err := yylex.currRune()
+	if err != nil {
+		yylex.text = yylex.in.buf[yylex.textPos:yylex.beginPos]
+		if errors.Is(err, io.EOF) {
+			err = io.ErrUnexpectedEOF
+		}
+		return 0, 0, err
+	}
+	yylex.extend(size)
+
+	return r, size, nil
+}
+
+// ReadUntil reads the input until the first occurrence of delim and appends it to the current token.
+// It returns the read text including delim.
+// If the input ends before delim, it reads the rest and returns io.ErrUnexpectedEOF.
+func (yylex *syncLexer) ReadUntil(delim string) (string, error) {
+	d := syncStringBytes(delim)
+	searched := 0 // number of bytes from the read position which do not begin delim
+	for {
+		rest := yylex.in.buf[yylex.beginPos:]
+		if i := bytes.Index(rest[searched:], d); i >= 0 {
+			n := searched + i + len(d)
+			s := yylex.textString(rest[:n])
+			yylex.extend(n)
+			return s, nil
+		}
+		if len(rest) >= len(d) {
+			searched = len(rest) - len(d) + 1
+		}
+		if err := yylex.fill(); err != nil {
+			s := yylex.textString(yylex.in.buf[yylex.beginPos:])
+			yylex.extend(len(yylex.in.buf) - yylex.beginPos)
+			if errors.Is(err, io.EOF) {
+				err = io.ErrUnexpectedEOF
+			}
+			return s, err
+		}
+	}
+}
+
+// Consume reads n bytes of the input and appends them to the current token.
+// If the input ends before n bytes, it reads the rest and returns io.ErrUnexpectedEOF.
+// It returns the number of bytes read, and ErrSyncNegativeCount if n is negative.
+func (yylex *syncLexer) Consume(n int) (int, error) {
+	if n < 0 {
+		return 0, ErrSyncNegativeCount
+	}
+	for len(yylex.in.buf)-yylex.beginPos < n {
+		if err := yylex.fill(); err != nil {
+			m := len(yylex.in.buf) - yylex.beginPos
+			yylex.extend(m)
+			if errors.Is(err, io.EOF) {
+				err = io.ErrUnexpectedEOF
+			}
+			return m, err
+		}
+	}
+	yylex.extend(n)
+
+	return n, nil
+}
+
+// extend appends n bytes at the read position to the current token.
+func (yylex *syncLexer) extend(n int) {
+	yylex.in.advance(yylex.in.buf[yylex.beginPos : yylex.beginPos+n])
+	yylex.beginPos += n
+	yylex.finPos = yylex.beginPos
+	yylex.currPos = yylex.beginPos
+	yylex.text = yylex.in.buf[yylex.textPos:yylex.beginPos]
+	yylex.YYText = yylex.textString(yylex.text)
+	yylex.end = yylex.in.position
+}
+
+// More makes the next token appended to the current text instead of replacing it.
+// It corresponds to yymore of lex. Pos() of the next token is the beginning of the current one.
+// The current text is dropped if the next runes match no rule or the input is switched.
+func (yylex *syncLexer) More() {
+	yylex.more = true
+}
+
+// Unput pushes s back to the input, and it is scanned before the rest of the input.
+// It corresponds to unput of lex. Positions count s as a part of the input.
+// The input is never modified; the current text, s and the buffered bytes are copied to a new buffer.
+// The rest of an in-memory input is read through a reader afterwards instead of being copied,
+// so texts of the following tokens do not share the memory with the input.
+func (yylex *syncLexer) Unput(s string) {
+	in := &yylex.in
+	n := len(yylex.text)
+	rest := in.buf[yylex.beginPos:]
+	if in.r == nil {
+		in.r = bytes.NewReader(rest)
+		in.err = nil
+		rest = nil
+	}
+	buf := make([]byte, 0, n+len(s)+len(rest)+syncBufSize)
+	buf = append(buf, in.buf[yylex.textPos:yylex.beginPos]...)
+	buf = append(buf, s...)
+	buf = append(buf, rest...)
+	in.buf = buf
+	yylex.text = buf[:n]
+	yylex.textPos = 0
+	yylex.beginPos = n
+	yylex.finPos = n
+	yylex.currPos = n
+}
+
+// Begin switches the start condition. It corresponds to BEGIN of lex.
+func (yylex *syncLexer) Begin(cond int) {
+	yylex.cond = cond
+	yylex.currStateID = syncStartStates[cond]
+}
+
+// StartCondition returns the current start condition.
+func (yylex *syncLexer) StartCondition() int {
+	return yylex.cond
+}
+
+// PushState saves the current start condition on the stack and switches to cond.
+// It corresponds to yy_push_state of flex.
+func (yylex *syncLexer) PushState(cond int) {
+	yylex.condStack = append(yylex.condStack, yylex.cond)
+	yylex.Begin(cond)
+}
+
+// PopState switches to the start condition on the top of the stack and removes it.
+// It returns ErrSyncEmptyStack if the stack is empty.
+func (yylex *syncLexer) PopState() error {
+	n := len(yylex.condStack)
+	if n == 0 {
+		return ErrSyncEmptyStack
+	}
+	cond := yylex.condStack[n-1]
+	yylex.condStack = yylex.condStack[:n-1]
+	yylex.Begin(cond)
+
+	return nil
+}
+
+// TopState returns the start condition on the top of the stack.
+// It returns ErrSyncEmptyStack if the stack is empty.
+func (yylex *syncLexer) TopState() (int, error) {
+	n := len(yylex.condStack)
+	if n == 0 {
+		return 0, ErrSyncEmptyStack
+	}
+
+	return yylex.condStack[n-1], nil
+}
+
+// StateStack returns a copy of the start condition stack. The last element is the top.
+func (yylex *syncLexer) StateStack() []int {
+	return append([]int{}, yylex.condStack...)
+}
+
+// PushInput suspends the current input and starts reading r.
+// The suspended input is resumed when r is exhausted or PopInput is called.
+func (yylex *syncLexer) PushInput(r io.Reader) {
+	in := yylex.in
+	in.pos = yylex.currPos
+	yylex.inputStack = append(yylex.inputStack, in)
+	yylex.in = syncInput{r: r, position: syncStartPosition}
+	yylex.textPos = 0
+	yylex.more = false
+	yylex.beginPos = 0
+	yylex.finPos = 0
+	yylex.currPos = 0
+	yylex.finRegexID = 0
+	yylex.currStateID = syncStartStates[yylex.cond]
+	yylex.inputID++
+}
+
+// PopInput discards the current input and resumes the last suspended one.
+// It returns false if there is no suspended input.
+func (yylex *syncLexer) PopInput() bool {
+	n := len(yylex.inputStack)
+	if n == 0 {
+		return false
+	}
+	yylex.in = yylex.inputStack[n-1]
+	yylex.inputStack = yylex.inputStack[:n-1]
+	yylex.textPos = yylex.in.pos
+	yylex.more = false
+	yylex.beginPos = yylex.in.pos
+	yylex.finPos = yylex.in.pos
+	yylex.currPos = yylex.in.pos
+	yylex.finRegexID = 0
+	yylex.currStateID = syncStartStates[yylex.cond]
+	yylex.inputID++
+
+	return true
+}
@@ -0,0 +1,71 @@
+package reentrant
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// words in parentheses and numbers in comments are skipped by start conditions of each lexer.
+const input = "abc 123 (de 45) f # 6 g\n7"
+
+func words(t *testing.T) []string {
+	lex := NewWord(strings.NewReader(input))
+	texts := make([]string, 0)
+	for {
+		kind, err := lex.Next()
+		if err != nil {
+			break
+		}
+		if kind != Word {
+			t.Fatalf("expected %v but %v", Word, kind)
+		}
+		texts = append(texts, lex.YYText)
+	}
+
+	return texts
+}
+
+func numbers(t *testing.T) []string {
+	lex := NewNumber(strings.NewReader(input))
+	texts := make([]string, 0)
+	for {
+		kind, err := lex.Next()
+		if err != nil {
+			break
+		}
+		if kind != Number {
+			t.Fatalf("expected %v but %v", Number, kind)
+		}
+		texts = append(texts, lex.YYText)
+	}
+
+	return texts
+}
+
+func TestParallel(t *testing.T) {
+	for i := 0; i < 8; i++ {
+		t.Run(fmt.Sprint("word", i), func(t *testing.T) {
+			t.Parallel()
+			if got := words(t); !reflect.DeepEqual([]string{"abc", "f", "g"}, got) {
+				t.Errorf("unexpected words: %q", got)
+			}
+		})
+		t.Run(fmt.Sprint("number", i), func(t *testing.T) {
+			t.Parallel()
+			if got := numbers(t); !reflect.DeepEqual([]string{"123", "45", "7"}, got) {
+				t.Errorf("unexpected numbers: %q", got)
+			}
+		})
+	}
+}
+
+func TestTokenKind(t *testing.T) {
+	if Word.String() != "Word" || Number.String() != "Number" {
+		t.Errorf("unexpected names: %v, %v", Word, Number)
+	}
+	if WordTokenKindByName["Word"] != Word || NumberTokenKindByName["Number"] != Number {
+		t.Errorf("unexpected kinds")
+	}
+}
@@ -0,0 +1,90 @@
+package stack
+
+import (
+	"errors"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestStateStack(t *testing.T) {
+	tests := []struct {
+		name     string
+		given    string
+		expected []TokenKind
+		texts    []string
+		err      error
+	}{
+		{
+			name:     "template string",
+			given:    "a `x${b}y` c",
+			expected: []TokenKind{Ident, Quote, Text, InterpStart, Ident, RBrace, Text, Quote, Ident},
+			texts:    []string{"a", "`", "x", "${", "b", "}", "y", "`", "c"},
+		},
+		{
+			name:     "nested template string",
+			given:    "`a${ {b} `c${d}` }$e`",
+			expected: []TokenKind{Quote, Text, InterpStart, LBrace, Ident, RBrace, Quote, Text, InterpStart, Ident, RBrace, Quote, RBrace, Text, Text, Quote},
+			texts:    []string{"`", "a", "${", "{", "b", "}", "`", "c", "${", "d", "}", "`", "}", "$", "e", "`"},
+		},
+		{
+			name:     "empty stack",
+			given:    "a }",
+			expected: []TokenKind{Ident},
+			texts:    []string{"a"},
+			err:      ErrYYEmptyStack,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			lex := New(strings.NewReader(tt.given))
+			kinds := make([]TokenKind, 0)
+			texts := make([]string, 0)
+			var err error
+			for {
+				var n TokenKind
+				n, err = lex.Next()
+				if err != nil {
+					break
+				}
+				kinds = append(kinds, n)
+				texts = append(texts, lex.YYText)
+			}
+
+			if tt.err != nil {
+				if !errors.Is(err, tt.err) {
+					t.Fatalf("expected %v but %v", tt.err, err)
+				}
+			} else if !errors.Is(err, io.EOF) {
+				t.Fatalf("expected io.EOF but %v", err)
+			}
+			if !reflect.DeepEqual(tt.expected, kinds) {
+				t.Fatalf("expected %v but %v", tt.expected, kinds)
+			}
+			if !reflect.DeepEqual(tt.texts, texts) {
+				t.Fatalf("expected %q but %q", tt.texts, texts)
+			}
+		})
+	}
+}
+
+func TestStateStack_Inspect(t *testing.T) {
+	lex := New(strings.NewReader("`a${ {"))
+	for i := 0; i < 4; i++ {
+		if _, err := lex.Next(); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if !reflect.DeepEqual([]int{INITIAL, TMPL, INITIAL}, lex.StateStack()) {
+		t.Errorf("unexpected stack: %v", lex.StateStack())
+	}
+	if top, err := lex.TopState(); err != nil || top != INITIAL {
+		t.Errorf("unexpected top: %v, %v", top, err)
+	}
+	if lex.StartCondition() != INITIAL {
+		t.Errorf("unexpected condition: %v", lex.StartCondition())
+	}
+}
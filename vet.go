@@ -0,0 +1,44 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/goropikari/tlex/compiler/generator"
+)
+
+// runVet runs `tlex vet [-option name=value] file.l...` and returns the exit code.
+// It is 1 when an error-level finding or an invalid file is found.
+func runVet(args []string) int {
+	fs := flag.NewFlagSet("vet", flag.ExitOnError)
+	var opts optionFlags
+	fs.Var(&opts, "option", "override %option of the configuration file")
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage: tlex vet [-option name=value] file.l...\n")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+	if fs.NArg() == 0 {
+		fs.Usage()
+		return 2
+	}
+
+	code := 0
+	for _, file := range fs.Args() {
+		spec, err := generator.ParseFile(file, opts...)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			code = 1
+			continue
+		}
+		for _, d := range generator.Vet(spec) {
+			fmt.Fprintln(os.Stderr, d)
+			if d.Severity == generator.SeverityError {
+				code = 1
+			}
+		}
+	}
+
+	return code
+}